@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/lemon-mint/coord/llm"
 	"github.com/lemon-mint/coord/pconf"
 	"github.com/lemon-mint/coord/provider"
 )
@@ -19,7 +20,34 @@ func NewLLMClient(ctx context.Context, provider string, configs ...pconf.Config)
 		return nil, ErrNoSuchProvider
 	}
 
-	return driver.NewLLMClient(ctx, configs...)
+	client, err := driver.NewLLMClient(ctx, configs...)
+	if err != nil {
+		return nil, err
+	}
+
+	client_config := pconf.GeneralConfig{}
+	for i := range configs {
+		configs[i].Apply(&client_config)
+	}
+	if len(client_config.LLMInterceptors) > 0 {
+		client = &interceptedLLMClient{LLMClient: client, interceptors: client_config.LLMInterceptors}
+	}
+
+	return client, nil
+}
+
+// interceptedLLMClient wraps every model of the client with the interceptors.
+type interceptedLLMClient struct {
+	provider.LLMClient
+	interceptors []llm.Interceptor
+}
+
+func (c *interceptedLLMClient) NewLLM(model string, config *llm.Config) (llm.Model, error) {
+	m, err := c.LLMClient.NewLLM(model, config)
+	if err != nil {
+		return nil, err
+	}
+	return llm.Chain(m, c.interceptors...), nil
 }
 
 func NewEmbeddingClient(ctx context.Context, provider string, configs ...pconf.Config) (provider.EmbeddingClient, error) {
@@ -45,3 +73,27 @@ func NewTTSClient(ctx context.Context, provider string, configs ...pconf.Config)
 
 	return driver.NewTTSClient(ctx, configs...)
 }
+
+func NewSTTClient(ctx context.Context, provider string, configs ...pconf.Config) (provider.STTClient, error) {
+	sttProvidersMu.RLock()
+	defer sttProvidersMu.RUnlock()
+
+	driver, ok := sttProviders[provider]
+	if !ok {
+		return nil, ErrNoSuchProvider
+	}
+
+	return driver.NewSTTClient(ctx, configs...)
+}
+
+func NewRerankClient(ctx context.Context, provider string, configs ...pconf.Config) (provider.RerankClient, error) {
+	rerankProvidersMu.RLock()
+	defer rerankProvidersMu.RUnlock()
+
+	driver, ok := rerankProviders[provider]
+	if !ok {
+		return nil, ErrNoSuchProvider
+	}
+
+	return driver.NewRerankClient(ctx, configs...)
+}
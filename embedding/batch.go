@@ -0,0 +1,65 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+)
+
+// BatchError reports the input that caused a batch embedding call to fail.
+// If the provider rejected a whole chunk of inputs, Index is the position of
+// the first input in that chunk.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("embedding: batch input %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// BatchTextEmbedding returns the embeddings of the texts in input order.
+// If the model implements BatchModel, the texts are embedded with its native batch API,
+// otherwise TextEmbedding is called for each text.
+func BatchTextEmbedding(ctx context.Context, m Model, texts []string, task TaskType) ([][]float64, error) {
+	if bm, ok := m.(BatchModel); ok {
+		return bm.BatchTextEmbedding(ctx, texts, task)
+	}
+
+	output := make([][]float64, len(texts))
+	for i := range texts {
+		v, err := m.TextEmbedding(ctx, texts[i], task)
+		if err != nil {
+			return nil, &BatchError{Index: i, Err: err}
+		}
+		output[i] = v
+	}
+
+	return output, nil
+}
+
+// EmbedTexts returns the embeddings of the texts in input order.
+// If the model implements ResultModel, the result includes the usage data reported by the provider,
+// otherwise the embeddings are computed with BatchTextEmbedding and UsageData is nil.
+func EmbedTexts(ctx context.Context, m Model, texts []string, task TaskType) (*Result, error) {
+	if rm, ok := m.(ResultModel); ok {
+		return rm.EmbedTexts(ctx, texts, task)
+	}
+
+	values, err := BatchTextEmbedding(ctx, m, texts, task)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Embeddings: make([]*Embedding, len(values)),
+	}
+	for i := range values {
+		result.Embeddings[i] = &Embedding{Values: values[i]}
+	}
+
+	return result, nil
+}
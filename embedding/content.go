@@ -0,0 +1,39 @@
+package embedding
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+// ContentEmbedding returns the embedding of the content.
+// If the model does not implement ContentModel, the text segments are concatenated and embedded with TextEmbedding,
+// and ErrUnsupported is returned if the content contains any other segment.
+func ContentEmbedding(ctx context.Context, m Model, parts []llm.Segment, task TaskType) ([]float64, error) {
+	if cm, ok := m.(ContentModel); ok {
+		return cm.ContentEmbedding(ctx, parts, task)
+	}
+
+	text, err := TextContent(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.TextEmbedding(ctx, text, task)
+}
+
+// TextContent concatenates the text segments of parts.
+// It returns ErrUnsupported if parts contains a segment that is not llm.Text.
+func TextContent(parts []llm.Segment) (string, error) {
+	var sb strings.Builder
+	for i := range parts {
+		t, ok := parts[i].(llm.Text)
+		if !ok {
+			return "", ErrUnsupported
+		}
+		sb.WriteString(string(t))
+	}
+
+	return sb.String(), nil
+}
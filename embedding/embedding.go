@@ -3,12 +3,63 @@ package embedding
 import (
 	"context"
 	"errors"
+
+	"github.com/lemon-mint/coord/llm"
 )
 
 type Model interface {
 	TextEmbedding(ctx context.Context, text string, task TaskType) ([]float64, error) // Returns the embedding of the text.
 }
 
+// BatchModel is implemented by models that can embed multiple texts in a single call.
+type BatchModel interface {
+	Model
+	BatchTextEmbedding(ctx context.Context, texts []string, task TaskType) ([][]float64, error) // Returns the embeddings of the texts in input order.
+}
+
+// ResultModel is implemented by models that can report token usage alongside the embeddings.
+type ResultModel interface {
+	Model
+	EmbedTexts(ctx context.Context, texts []string, task TaskType) (*Result, error) // Returns the embeddings of the texts in input order with usage data.
+}
+
+// ContentModel is implemented by models that can embed content other than plain text, such as images.
+// Text-only models return ErrUnsupported for segments that are not llm.Text.
+type ContentModel interface {
+	Model
+	ContentEmbedding(ctx context.Context, parts []llm.Segment, task TaskType) ([]float64, error) // Returns the embedding of the content.
+}
+
+type UsageData struct {
+	InputTokens        int
+	BillableCharacters int // Only reported by Vertex AI
+}
+
+type Embedding struct {
+	Values      []float64 `json:"values"`
+	InputTokens int       `json:"inputTokens,omitempty"` // Number of tokens of the input (Note: not reported by all embedding providers)
+	Truncated   bool      `json:"truncated,omitempty"`   // True if the input was truncated to the maximum length of the model
+}
+
+type Result struct {
+	Embeddings []*Embedding `json:"embeddings"`
+	UsageData  *UsageData   `json:"usageData"` // Note: UsageData is not available for all embedding providers
+}
+
+// Values returns the embedding vectors of the result in input order.
+func (r *Result) Values() [][]float64 {
+	if r == nil {
+		return nil
+	}
+
+	values := make([][]float64, len(r.Embeddings))
+	for i := range r.Embeddings {
+		values[i] = r.Embeddings[i].Values
+	}
+
+	return values
+}
+
 var (
 	ErrUnsupported       = errors.New("unsupported")         // This Error occurs whem the model does not support the content type provided.
 	ErrMaxLengthExceeded = errors.New("max length exceeded") // This Error occurs when the content exceeds the maximum length.
@@ -29,6 +80,31 @@ const (
 	TaskTypeQA
 )
 
+//go:generate go tool stringer -type=OutputType
+type OutputType uint16
+
+const (
+	OutputTypeFloat   OutputType = iota // Full precision values as returned by the provider.
+	OutputTypeFloat32                   // Values rounded to float32 precision.
+	OutputTypeInt8                      // Values quantized to [-128, 127].
+	OutputTypeUint8                     // Values quantized to [0, 255].
+	OutputTypeBinary                    // Sign bits packed into bytes (8 dimensions per value), offset to [-128, 127].
+	OutputTypeUbinary                   // Sign bits packed into bytes (8 dimensions per value) in [0, 255].
+)
+
 type Config struct {
 	Dimension int
+
+	// OutputType is the data type of the returned values.
+	// Quantized values are still returned as []float64, each element holding one integer value.
+	// If the provider does not support the type natively, the embeddings are L2-normalized and quantized client-side.
+	OutputType OutputType
+
+	// Normalize L2-normalizes the returned embeddings, even if the provider does not.
+	Normalize bool
+
+	// AutoTruncate controls whether inputs exceeding the maximum length of the model are truncated.
+	// If false, such inputs fail with ErrMaxLengthExceeded. If nil, the provider default is used.
+	// Providers that can not disable truncation return ErrUnsupported when the model is created.
+	AutoTruncate *bool
 }
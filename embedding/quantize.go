@@ -0,0 +1,77 @@
+package embedding
+
+import "math"
+
+// Normalize scales v to unit L2 norm in place and returns it.
+func Normalize(v []float64) []float64 {
+	var norm float64
+	for i := range v {
+		norm += v[i] * v[i]
+	}
+
+	if norm == 0 {
+		return v
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] /= norm
+	}
+
+	return v
+}
+
+// Quantize converts the L2-normalized embedding v to the output type t.
+// Binary output types pack 8 dimensions into each returned value, most significant bit first.
+func Quantize(v []float64, t OutputType) []float64 {
+	switch t {
+	case OutputTypeFloat32:
+		output := make([]float64, len(v))
+		for i := range v {
+			output[i] = float64(float32(v[i]))
+		}
+		return output
+	case OutputTypeInt8:
+		output := make([]float64, len(v))
+		for i := range v {
+			output[i] = math.Round(clamp(v[i]) * 127)
+		}
+		return output
+	case OutputTypeUint8:
+		output := make([]float64, len(v))
+		for i := range v {
+			output[i] = math.Round((clamp(v[i]) + 1) * 127.5)
+		}
+		return output
+	case OutputTypeBinary, OutputTypeUbinary:
+		output := make([]float64, (len(v)+7)/8)
+		for i := range output {
+			var b uint8
+			for j := 0; j < 8; j++ {
+				b <<= 1
+				if k := i*8 + j; k < len(v) && v[k] > 0 {
+					b |= 1
+				}
+			}
+
+			if t == OutputTypeBinary {
+				output[i] = float64(int(b) - 128)
+			} else {
+				output[i] = float64(b)
+			}
+		}
+		return output
+	}
+
+	return v
+}
+
+func clamp(x float64) float64 {
+	if x > 1 {
+		return 1
+	}
+	if x < -1 {
+		return -1
+	}
+	return x
+}
@@ -0,0 +1,40 @@
+package embedding_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lemon-mint/coord/embedding"
+)
+
+func TestNormalize(t *testing.T) {
+	v := embedding.Normalize([]float64{3, 4})
+	if math.Abs(v[0]-0.6) > 1e-9 || math.Abs(v[1]-0.8) > 1e-9 {
+		t.Errorf("unexpected normalized vector: %v", v)
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	v := []float64{1, -1, 0.5, -0.5, 0, 0.25, -0.25, 0.1, 0.2}
+
+	int8s := embedding.Quantize(v, embedding.OutputTypeInt8)
+	if int8s[0] != 127 || int8s[1] != -127 || int8s[4] != 0 {
+		t.Errorf("unexpected int8 output: %v", int8s)
+	}
+
+	uint8s := embedding.Quantize(v, embedding.OutputTypeUint8)
+	if uint8s[0] != 255 || uint8s[1] != 0 {
+		t.Errorf("unexpected uint8 output: %v", uint8s)
+	}
+
+	// 1 0 1 0 0 1 0 1 | 1 0 0 0 0 0 0 0
+	ubinary := embedding.Quantize(v, embedding.OutputTypeUbinary)
+	if len(ubinary) != 2 || ubinary[0] != 0b10100101 || ubinary[1] != 0b10000000 {
+		t.Errorf("unexpected ubinary output: %v", ubinary)
+	}
+
+	binary := embedding.Quantize(v, embedding.OutputTypeBinary)
+	if binary[0] != 0b10100101-128 || binary[1] != 0 {
+		t.Errorf("unexpected binary output: %v", binary)
+	}
+}
@@ -54,13 +54,9 @@ func main() {
 	}
 
 	texts := []string{"Apple", "Banana", "Cat", "Hamster"}
-	embeddings := make([][]float64, len(texts))
-	for i := range texts {
-		embedding, err := model.TextEmbedding(context.Background(), texts[i], embedding.TaskTypeSemanticSimilarity)
-		if err != nil {
-			panic(err)
-		}
-		embeddings[i] = embedding
+	embeddings, err := embedding.BatchTextEmbedding(context.Background(), model, texts, embedding.TaskTypeSemanticSimilarity)
+	if err != nil {
+		panic(err)
 	}
 
 	// Calculate cosine similarity between the embeddings using a loop
@@ -8,7 +8,7 @@ require (
 	cloud.google.com/go/auth v0.16.0
 	cloud.google.com/go/texttospeech v1.10.0
 	github.com/goccy/go-yaml v1.12.0
-	github.com/sashabaranov/go-openai v1.29.0
+	github.com/sashabaranov/go-openai v1.41.2
 	github.com/valyala/fastjson v1.6.4
 	golang.org/x/crypto v0.37.0
 	golang.org/x/sys v0.32.0
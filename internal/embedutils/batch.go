@@ -0,0 +1,71 @@
+package embedutils
+
+import (
+	"errors"
+
+	"github.com/lemon-mint/coord/embedding"
+)
+
+// Batch splits inputs into chunks of at most size elements and calls fn for each chunk.
+// The outputs are concatenated in input order. Errors are reported as *embedding.BatchError
+// with the index relative to the whole input.
+func Batch[I, O any](inputs []I, size int, fn func(inputs []I) ([]O, error)) ([]O, error) {
+	if size <= 0 {
+		size = len(inputs)
+	}
+
+	output := make([]O, 0, len(inputs))
+	for offset := 0; offset < len(inputs); offset += size {
+		end := offset + size
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+
+		chunk, err := fn(inputs[offset:end])
+		if err != nil {
+			var berr *embedding.BatchError
+			if errors.As(err, &berr) {
+				return nil, &embedding.BatchError{Index: offset + berr.Index, Err: berr.Err}
+			}
+			return nil, &embedding.BatchError{Index: offset, Err: err}
+		}
+
+		if len(chunk) != end-offset {
+			return nil, &embedding.BatchError{Index: offset, Err: embedding.ErrInvalidResponse}
+		}
+
+		output = append(output, chunk...)
+	}
+
+	return output, nil
+}
+
+// BatchResult is like Batch, but merges the results and usage data of each chunk.
+func BatchResult[I any](inputs []I, size int, fn func(inputs []I) (*embedding.Result, error)) (*embedding.Result, error) {
+	var usage *embedding.UsageData
+
+	embeddings, err := Batch(inputs, size, func(inputs []I) ([]*embedding.Embedding, error) {
+		result, err := fn(inputs)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.UsageData != nil {
+			if usage == nil {
+				usage = new(embedding.UsageData)
+			}
+			usage.InputTokens += result.UsageData.InputTokens
+			usage.BillableCharacters += result.UsageData.BillableCharacters
+		}
+
+		return result.Embeddings, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &embedding.Result{
+		Embeddings: embeddings,
+		UsageData:  usage,
+	}, nil
+}
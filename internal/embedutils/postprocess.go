@@ -0,0 +1,14 @@
+package embedutils
+
+import "github.com/lemon-mint/coord/embedding"
+
+// Postprocess applies the normalization and quantization requested by the config client-side.
+// It is used by providers that only return float embeddings.
+func Postprocess(values []float64, normalize bool, outputType embedding.OutputType) []float64 {
+	quantized := outputType != embedding.OutputTypeFloat && outputType != embedding.OutputTypeFloat32
+	if normalize || quantized {
+		values = embedding.Normalize(values)
+	}
+
+	return embedding.Quantize(values, outputType)
+}
@@ -0,0 +1,110 @@
+package llmtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+// Model is a fake llm.Model for the tests of model wrappers. A request streams Parts and
+// answers with them as its content, or fails with Err after streaming Partial.
+type Model struct {
+	ModelName string          // Name of the model, also reported as the model version (default: "fake")
+	Parts     []llm.Segment   // Segments of a successful response (default: the parts of the input)
+	Err       error           // Error of the failing requests
+	Failures  int             // Number of requests that fail with Err (0 for all requests if Err is set)
+	Partial   []llm.Segment   // Segments streamed by a failing request before it fails
+	Usage     *llm.UsageData  // Usage reported by each request
+	Delay     time.Duration   // Time each request takes before its stream is closed
+	Block     <-chan struct{} // Holds the responses open until closed if set
+
+	mu          sync.Mutex
+	calls       int
+	inflight    int
+	maxInflight int
+}
+
+var _ llm.Model = (*Model)(nil)
+
+func (m *Model) GenerateStream(ctx context.Context, chat *llm.ChatContext, input *llm.Content) *llm.StreamContent {
+	m.mu.Lock()
+	m.calls++
+	m.inflight++
+	m.maxInflight = max(m.maxInflight, m.inflight)
+	failed := m.Err != nil && (m.Failures == 0 || m.calls <= m.Failures)
+	m.mu.Unlock()
+
+	stream := make(chan llm.Segment)
+	v := &llm.StreamContent{
+		Stream: stream,
+	}
+
+	parts := m.Parts
+	if parts == nil && input != nil {
+		parts = input.Parts
+	}
+	if failed {
+		parts = m.Partial
+	}
+
+	go func() {
+		defer close(stream)
+		defer func() {
+			m.mu.Lock()
+			m.inflight--
+			m.mu.Unlock()
+		}()
+
+		for _, segment := range parts {
+			select {
+			case stream <- segment:
+			case <-ctx.Done():
+				v.Err = ctx.Err()
+				return
+			}
+		}
+
+		if m.Delay > 0 {
+			time.Sleep(m.Delay)
+		}
+		if m.Block != nil {
+			<-m.Block
+		}
+
+		v.UsageData = m.Usage
+		v.Model = m.Name()
+		if failed {
+			v.Err = m.Err
+			return
+		}
+		v.Content = &llm.Content{Role: llm.RoleModel, Parts: parts}
+		v.FinishReason = llm.FinishReasonStop
+	}()
+
+	return v
+}
+
+func (m *Model) Close() error { return nil }
+
+func (m *Model) Name() string {
+	if m.ModelName == "" {
+		return "fake"
+	}
+	return m.ModelName
+}
+
+// Calls returns the number of requests to the model.
+func (m *Model) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// MaxInflight returns the highest number of requests that were in flight at the same time.
+func (m *Model) MaxInflight() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.maxInflight
+}
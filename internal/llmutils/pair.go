@@ -0,0 +1,119 @@
+package llmutils
+
+import "github.com/lemon-mint/coord/llm"
+
+// PairFunctionResponses matches the function responses of each RoleFunc content with the
+// function calls of the preceding model content by ID, and returns the contents with the
+// responses reordered to the order of the calls. A response without an ID is paired with
+// the first unanswered call of the same name, or else the first unanswered call. A response
+// without a name takes the name of its call.
+// Responses that match no call are kept after the paired ones. The contents are not modified.
+func PairFunctionResponses(history []*llm.Content, input *llm.Content) ([]*llm.Content, *llm.Content) {
+	var calls []*llm.FunctionCall
+
+	pair := func(c *llm.Content) *llm.Content {
+		if c == nil {
+			return nil
+		}
+
+		switch c.Role {
+		case llm.RoleModel:
+			calls = calls[:0]
+			for i := range c.Parts {
+				if fc, ok := c.Parts[i].(*llm.FunctionCall); ok {
+					calls = append(calls, fc)
+				}
+			}
+		case llm.RoleFunc:
+			c = pairContent(c, calls)
+			calls = calls[:0]
+		default:
+			calls = calls[:0]
+		}
+		return c
+	}
+
+	paired := make([]*llm.Content, len(history))
+	for i := range history {
+		paired[i] = pair(history[i])
+	}
+
+	return paired, pair(input)
+}
+
+func pairContent(c *llm.Content, calls []*llm.FunctionCall) *llm.Content {
+	if len(calls) == 0 {
+		return c
+	}
+
+	index := make(map[string]int, len(calls))
+	for i := range calls {
+		if _, ok := index[calls[i].ID]; !ok && calls[i].ID != "" {
+			index[calls[i].ID] = i
+		}
+	}
+
+	paired := make([]*llm.FunctionResponse, len(calls))
+	var unpaired []*llm.FunctionResponse
+	var others []llm.Segment
+
+	for i := range c.Parts {
+		fr, ok := c.Parts[i].(*llm.FunctionResponse)
+		if !ok {
+			others = append(others, c.Parts[i])
+			continue
+		}
+
+		if idx, ok := index[fr.ID]; ok && paired[idx] == nil {
+			paired[idx] = fr
+			continue
+		}
+		unpaired = append(unpaired, fr)
+	}
+
+	var extra []llm.Segment
+	for _, fr := range unpaired {
+		idx := -1
+		if fr.ID == "" {
+			for i := range paired {
+				if paired[i] != nil {
+					continue
+				}
+				if calls[i].Name == fr.Name {
+					idx = i
+					break
+				}
+				if idx < 0 {
+					idx = i
+				}
+			}
+		}
+		if idx < 0 {
+			extra = append(extra, fr)
+			continue
+		}
+
+		v := *fr
+		v.ID = calls[idx].ID
+		paired[idx] = &v
+	}
+
+	parts := make([]llm.Segment, 0, len(c.Parts))
+	for i, fr := range paired {
+		if fr == nil {
+			continue
+		}
+		if fr.Name == "" {
+			v := *fr
+			v.Name = calls[i].Name
+			fr = &v
+		}
+		parts = append(parts, fr)
+	}
+	parts = append(parts, extra...)
+	parts = append(parts, others...)
+
+	paired_content := *c
+	paired_content.Parts = parts
+	return &paired_content
+}
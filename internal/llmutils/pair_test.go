@@ -0,0 +1,51 @@
+package llmutils_test
+
+import (
+	"testing"
+
+	"github.com/lemon-mint/coord/internal/llmutils"
+	"github.com/lemon-mint/coord/llm"
+)
+
+func TestPairFunctionResponses(t *testing.T) {
+	history := []*llm.Content{
+		llm.TextContent(llm.RoleUser, "Weather in Seoul and Tokyo?"),
+		{
+			Role: llm.RoleModel,
+			Parts: []llm.Segment{
+				&llm.FunctionCall{ID: "a", Name: "get_weather"},
+				&llm.FunctionCall{ID: "b", Name: "get_time"},
+				&llm.FunctionCall{ID: "c", Name: "get_weather"},
+			},
+		},
+	}
+	input := &llm.Content{
+		Role: llm.RoleFunc,
+		Parts: []llm.Segment{
+			&llm.FunctionResponse{ID: "c", Content: "rainy"},
+			&llm.FunctionResponse{Name: "get_time", Content: "12:00"},
+			&llm.FunctionResponse{ID: "a", Content: "sunny"},
+			&llm.FunctionResponse{ID: "x", Content: "unknown"},
+		},
+	}
+
+	paired, input := llmutils.PairFunctionResponses(history, input)
+	if len(paired) != len(history) || paired[1] != history[1] {
+		t.Fatalf("unexpected history: %v", paired)
+	}
+
+	expected := []llm.FunctionResponse{
+		{ID: "a", Name: "get_weather", Content: "sunny"},
+		{ID: "b", Name: "get_time", Content: "12:00"},
+		{ID: "c", Name: "get_weather", Content: "rainy"},
+		{ID: "x", Content: "unknown"},
+	}
+	if len(input.Parts) != len(expected) {
+		t.Fatalf("expected %d responses, got %d", len(expected), len(input.Parts))
+	}
+	for i := range expected {
+		if r := input.Parts[i].(*llm.FunctionResponse); *r != expected[i] {
+			t.Errorf("response %d: expected %+v, got %+v", i, expected[i], *r)
+		}
+	}
+}
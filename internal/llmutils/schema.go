@@ -0,0 +1,72 @@
+package llmutils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+// SchemaKeyword is a set of llm.Schema keywords.
+type SchemaKeyword uint
+
+const (
+	SchemaKeywordOneOf SchemaKeyword = 1 << iota
+	SchemaKeywordMinimum
+	SchemaKeywordMaximum
+	SchemaKeywordMinLength
+	SchemaKeywordMaxLength
+	SchemaKeywordPattern
+	SchemaKeywordMinItems
+	SchemaKeywordMaxItems
+	SchemaKeywordAdditionalProperties
+)
+
+// DescribeSchema returns the description of the schema with the constraints of the
+// given keywords appended, for providers that can not express the keywords natively.
+func DescribeSchema(s *llm.Schema, keywords SchemaKeyword) string {
+	var notes []string
+
+	if keywords&SchemaKeywordOneOf != 0 && len(s.OneOf) > 0 {
+		notes = append(notes, "Must match exactly one of the options.")
+	}
+	if keywords&SchemaKeywordMinimum != 0 && s.Minimum != nil {
+		notes = append(notes, fmt.Sprintf("Minimum value: %v.", *s.Minimum))
+	}
+	if keywords&SchemaKeywordMaximum != 0 && s.Maximum != nil {
+		notes = append(notes, fmt.Sprintf("Maximum value: %v.", *s.Maximum))
+	}
+	if keywords&SchemaKeywordMinLength != 0 && s.MinLength != nil {
+		notes = append(notes, fmt.Sprintf("Minimum length: %d.", *s.MinLength))
+	}
+	if keywords&SchemaKeywordMaxLength != 0 && s.MaxLength != nil {
+		notes = append(notes, fmt.Sprintf("Maximum length: %d.", *s.MaxLength))
+	}
+	if keywords&SchemaKeywordPattern != 0 && s.Pattern != "" {
+		notes = append(notes, fmt.Sprintf("Must match the pattern %s.", s.Pattern))
+	}
+	if keywords&SchemaKeywordMinItems != 0 && s.MinItems != nil {
+		notes = append(notes, fmt.Sprintf("Minimum number of items: %d.", *s.MinItems))
+	}
+	if keywords&SchemaKeywordMaxItems != 0 && s.MaxItems != nil {
+		notes = append(notes, fmt.Sprintf("Maximum number of items: %d.", *s.MaxItems))
+	}
+	if keywords&SchemaKeywordAdditionalProperties != 0 && s.AdditionalProperties != nil {
+		switch ap := s.AdditionalProperties; {
+		case !ap.Allowed && ap.Schema == nil:
+			notes = append(notes, "Properties other than the listed ones are not allowed.")
+		case ap.Schema != nil && ap.Schema.Type != "":
+			notes = append(notes, fmt.Sprintf("Additional properties must be of type %s.", ap.Schema.Type))
+		}
+	}
+
+	if len(notes) == 0 {
+		return s.Description
+	}
+
+	if s.Description == "" {
+		return strings.Join(notes, " ")
+	}
+
+	return s.Description + " " + strings.Join(notes, " ")
+}
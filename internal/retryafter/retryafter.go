@@ -0,0 +1,51 @@
+// Package retryafter reads the delay that a provider asks clients to wait before retrying.
+package retryafter
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+// Parse returns the delay in the retry-after-ms or Retry-After header of the response,
+// or zero if there is none. Retry-After is either a number of seconds or an HTTP date.
+func Parse(h http.Header, now time.Time) time.Duration {
+	if v := strings.TrimSpace(h.Get("Retry-After-Ms")); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+
+	if s, err := strconv.ParseFloat(v, 64); err == nil {
+		if s <= 0 {
+			return 0
+		}
+		return time.Duration(s * float64(time.Second))
+	}
+
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
+
+// Wrap returns err wrapped in an *llm.RetryAfterError if the response asks for a delay.
+func Wrap(err error, h http.Header) error {
+	if err == nil {
+		return nil
+	}
+
+	if d := Parse(h, time.Now()); d > 0 {
+		return &llm.RetryAfterError{Err: err, Delay: d}
+	}
+	return err
+}
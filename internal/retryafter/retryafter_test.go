@@ -0,0 +1,47 @@
+package retryafter_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lemon-mint/coord/internal/retryafter"
+	"github.com/lemon-mint/coord/llm"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header   http.Header
+		expected time.Duration
+	}{
+		{http.Header{}, 0},
+		{http.Header{"Retry-After": {"3"}}, 3 * time.Second},
+		{http.Header{"Retry-After": {"0.5"}}, 500 * time.Millisecond},
+		{http.Header{"Retry-After": {"Wed, 01 Jan 2025 00:00:10 GMT"}}, 10 * time.Second},
+		{http.Header{"Retry-After": {"Tue, 31 Dec 2024 23:59:00 GMT"}}, 0},
+		{http.Header{"Retry-After": {"soon"}}, 0},
+		{http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"1"}}, 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if d := retryafter.Parse(tt.header, now); d != tt.expected {
+			t.Errorf("%v: expected %v, got %v", tt.header, tt.expected, d)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	err := retryafter.Wrap(llm.ErrRateLimit, http.Header{"Retry-After": {"2"}})
+
+	var rerr *llm.RetryAfterError
+	if !errors.As(err, &rerr) || rerr.Delay != 2*time.Second || !errors.Is(err, llm.ErrRateLimit) {
+		t.Errorf("unexpected error: %#v", err)
+	}
+
+	if err := retryafter.Wrap(llm.ErrRateLimit, http.Header{}); err != llm.ErrRateLimit {
+		t.Errorf("expected the error to be returned as is, got %#v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package ttsutils
+
+import (
+	"context"
+	"io"
+
+	"github.com/lemon-mint/coord/tts"
+)
+
+// chunkSize is the maximum size of a chunk read from a streamed response body.
+const chunkSize = 8192
+
+// ReaderStream delivers the body as a stream of audio chunks and closes it
+// once it is exhausted or the context is cancelled.
+func ReaderStream(ctx context.Context, format tts.Format, body io.ReadCloser) *tts.AudioStream {
+	stream := make(chan []byte, 8)
+	v := &tts.AudioStream{
+		Format: format,
+		Stream: stream,
+	}
+
+	go func() {
+		defer close(stream)
+		defer body.Close()
+
+		for {
+			chunk := make([]byte, chunkSize)
+			n, err := body.Read(chunk)
+			if n > 0 {
+				select {
+				case stream <- chunk[:n]:
+				case <-ctx.Done():
+					v.Err = ctx.Err()
+					return
+				}
+			}
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				v.Err = err
+				return
+			}
+		}
+	}()
+
+	return v
+}
+
+// ErrorStream returns a closed stream that reports err.
+func ErrorStream(err error) *tts.AudioStream {
+	stream := make(chan []byte)
+	close(stream)
+
+	return &tts.AudioStream{
+		Err:    err,
+		Stream: stream,
+	}
+}
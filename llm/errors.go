@@ -1,6 +1,9 @@
 package llm
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrUnknown         = errors.New("unknown error")
@@ -14,3 +17,18 @@ var (
 	ErrOverloaded      = errors.New("overloaded")
 	ErrInternalServer  = errors.New("internal server error")
 )
+
+// RetryAfterError wraps an error with the delay that the provider asked to wait before
+// retrying (e.g. from a Retry-After header). Providers of every model type use it.
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error() + " (retry after " + e.Delay.String() + ")"
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
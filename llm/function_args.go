@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// ArgumentsError reports arguments of a function call that do not match the schema of
+// the function declaration. It can be sent back to the model with Response.
+type ArgumentsError struct {
+	Name   string             `json:"name"`   // Name of the function
+	Errors []*ValidationError `json:"errors"` // Invalid arguments (Note: at most one error per argument)
+}
+
+func (e *ArgumentsError) Error() string {
+	if len(e.Errors) == 1 {
+		return fmt.Sprintf("llm: invalid arguments for %s: %s: %s", e.Name, e.Errors[0].Path, e.Errors[0].Message)
+	}
+	return fmt.Sprintf("llm: invalid arguments for %s: %d errors", e.Name, len(e.Errors))
+}
+
+// Response returns a function response reporting the error for the call.
+func (e *ArgumentsError) Response(fc *FunctionCall) *FunctionResponse {
+	return &FunctionResponse{
+		Name: fc.Name,
+		ID:   fc.ID,
+		Content: map[string]interface{}{
+			"error":  e.Error(),
+			"errors": e.Errors,
+		},
+		IsError: true,
+	}
+}
+
+// CoerceArgs returns a copy of the arguments with the conversions of Schema.Coerce applied.
+func (d *FunctionDeclaration) CoerceArgs(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	if d.Schema == nil {
+		return args
+	}
+
+	if v, ok := d.Schema.Coerce(args).(map[string]interface{}); ok {
+		return v
+	}
+	return args
+}
+
+// ValidateArgs coerces the arguments with CoerceArgs and checks them against the schema of
+// the declaration. It returns the coerced arguments, or an *ArgumentsError listing every
+// invalid argument.
+func (d *FunctionDeclaration) ValidateArgs(args map[string]interface{}) (map[string]interface{}, error) {
+	args = d.CoerceArgs(args)
+
+	s := d.Schema
+	if s == nil {
+		return args, nil
+	}
+	e := &ArgumentsError{Name: d.Name}
+
+	if s.Ref != "" {
+		if s = s.Resolve(s.Ref); s == nil {
+			e.Errors = append(e.Errors, &ValidationError{
+				Path:    "$",
+				Message: "unresolved reference " + strconv.Quote(d.Schema.Ref),
+			})
+			return args, e
+		}
+	}
+
+	for _, name := range s.Required {
+		if _, ok := args[name]; !ok {
+			e.Errors = append(e.Errors, &ValidationError{
+				Path:    "$." + name,
+				Message: "missing required argument " + strconv.Quote(name),
+			})
+		}
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// Null optional arguments are treated as absent, as in Schema.Validate.
+		if args[name] == nil && !slices.Contains(s.Required, name) {
+			continue
+		}
+
+		ps, ok := s.Properties[name]
+		if !ok {
+			ap := s.AdditionalProperties
+			if ap != nil && !ap.Allowed && ap.Schema == nil {
+				e.Errors = append(e.Errors, &ValidationError{
+					Path:    "$." + name,
+					Message: "unexpected argument " + strconv.Quote(name),
+				})
+				continue
+			}
+			if ap == nil {
+				continue
+			}
+			ps = ap.Schema
+		}
+
+		if err := ps.validate(d.Schema, "$."+name, args[name], 1); err != nil {
+			e.Errors = append(e.Errors, err.(*ValidationError))
+		}
+	}
+
+	if len(e.Errors) == 0 {
+		// Keywords of the object itself, such as unions, are checked as a whole.
+		if err := s.validateUnion(d.Schema, "$", args, 0); err != nil {
+			e.Errors = append(e.Errors, err.(*ValidationError))
+		}
+	}
+
+	if len(e.Errors) > 0 {
+		return args, e
+	}
+	return args, nil
+}
@@ -0,0 +1,82 @@
+package llm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+func TestValidateArgs(t *testing.T) {
+	decl := &llm.FunctionDeclaration{
+		Name: "get_weather",
+		Schema: &llm.Schema{
+			Type: llm.OpenAPITypeObject,
+			Properties: map[string]*llm.Schema{
+				"location": {Type: llm.OpenAPITypeString},
+				"days":     {Type: llm.OpenAPITypeInteger},
+				"metric":   {Type: llm.OpenAPITypeBoolean},
+				"unit":     {Type: llm.OpenAPITypeString, Enum: []interface{}{"celsius", "fahrenheit"}},
+				"zip":      {Type: llm.OpenAPITypeString},
+			},
+			Required: []string{"location", "days"},
+		},
+	}
+
+	args, err := decl.ValidateArgs(map[string]interface{}{
+		"location": "Seoul",
+		"days":     "3",
+		"metric":   "true",
+		"zip":      uint64(12345),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args["days"] != 3.0 || args["metric"] != true || args["zip"] != "12345" {
+		t.Errorf("unexpected coerced arguments: %v", args)
+	}
+
+	if _, err := decl.ValidateArgs(map[string]interface{}{
+		"location": "Seoul",
+		"days":     3,
+		"unit":     nil,
+	}); err != nil {
+		t.Errorf("unexpected error for a null optional argument: %v", err)
+	}
+	if _, err := decl.ValidateArgs(map[string]interface{}{
+		"location": nil,
+		"days":     3,
+	}); err == nil {
+		t.Error("expected an error for a null required argument")
+	}
+
+	_, err = decl.ValidateArgs(map[string]interface{}{
+		"days": "three",
+		"unit": "kelvin",
+	})
+
+	var aerr *llm.ArgumentsError
+	if !errors.As(err, &aerr) {
+		t.Fatalf("expected an ArgumentsError, got %v", err)
+	}
+
+	paths := make([]string, len(aerr.Errors))
+	for i := range aerr.Errors {
+		paths[i] = aerr.Errors[i].Path
+	}
+	expected := []string{"$.location", "$.days", "$.unit"}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected errors at %v, got %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("expected errors at %v, got %v", expected, paths)
+			break
+		}
+	}
+
+	resp := aerr.Response(&llm.FunctionCall{Name: "get_weather", ID: "call_1"})
+	if !resp.IsError || resp.ID != "call_1" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
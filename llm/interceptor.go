@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+)
+
+// Interceptor wraps a model to run code around its requests, such as logging, metrics or retries.
+type Interceptor func(next Model) Model
+
+// Chain wraps m with the interceptors. The first interceptor is the outermost one and sees
+// each request first.
+func Chain(m Model, interceptors ...Interceptor) Model {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		m = interceptors[i](m)
+	}
+	return m
+}
+
+// GenerateStreamInterceptor returns an interceptor that calls fn for each request instead of
+// the next model. fn calls next.GenerateStream to continue the request. Close and Name are
+// passed through to the next model.
+func GenerateStreamInterceptor(fn func(ctx context.Context, chat *ChatContext, input *Content, next Model) *StreamContent) Interceptor {
+	return func(next Model) Model {
+		return &interceptedModel{next: next, fn: fn}
+	}
+}
+
+type interceptedModel struct {
+	next Model
+	fn   func(ctx context.Context, chat *ChatContext, input *Content, next Model) *StreamContent
+}
+
+func (m *interceptedModel) GenerateStream(ctx context.Context, chat *ChatContext, input *Content) *StreamContent {
+	return m.fn(ctx, chat, input, m.next)
+}
+
+func (m *interceptedModel) Close() error {
+	return m.next.Close()
+}
+
+func (m *interceptedModel) Name() string {
+	return m.next.Name()
+}
+
+type StreamHooks struct {
+	OnSegment func(ctx context.Context, s Segment) Segment // Called for each segment before it is delivered; returns the segment to deliver, or nil to drop it
+	OnDone    func(ctx context.Context, v *StreamContent)  // Called after the last segment with the result of the stream, before the stream is closed
+}
+
+// StreamHooksInterceptor returns an interceptor that calls the hooks while the response is streamed.
+// OnSegment only changes the delivered segments, not the final content, which OnDone can change.
+func StreamHooksInterceptor(hooks StreamHooks) Interceptor {
+	return GenerateStreamInterceptor(func(ctx context.Context, chat *ChatContext, input *Content, next Model) *StreamContent {
+		upstream := next.GenerateStream(ctx, chat, input)
+
+		stream := make(chan Segment, 128)
+		v := &StreamContent{
+			Stream: stream,
+		}
+
+		var fn func(Segment) Segment
+		if hooks.OnSegment != nil {
+			fn = func(s Segment) Segment {
+				return hooks.OnSegment(ctx, s)
+			}
+		}
+
+		go func() {
+			defer close(stream)
+
+			upstream.Forward(ctx, stream, v, fn)
+
+			if hooks.OnDone != nil {
+				hooks.OnDone(ctx, v)
+			}
+		}()
+
+		return v
+	})
+}
@@ -0,0 +1,64 @@
+package llm_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lemon-mint/coord/internal/llmtest"
+	"github.com/lemon-mint/coord/llm"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) llm.Interceptor {
+		return llm.GenerateStreamInterceptor(func(ctx context.Context, chat *llm.ChatContext, input *llm.Content, next llm.Model) *llm.StreamContent {
+			calls = append(calls, name)
+			return next.GenerateStream(ctx, chat, input)
+		})
+	}
+
+	model := llm.Chain(&llmtest.Model{ModelName: "echo"}, record("outer"), record("inner"))
+	if err := model.GenerateStream(context.Background(), nil, llm.TextContent(llm.RoleUser, "hi")).Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(calls, ","); got != "outer,inner" {
+		t.Errorf("calls = %s, want outer,inner", got)
+	}
+	if model.Name() != "echo" {
+		t.Errorf("Name = %q, want echo", model.Name())
+	}
+}
+
+func TestStreamHooksInterceptor(t *testing.T) {
+	var done bool
+	hooks := llm.StreamHooks{
+		OnSegment: func(ctx context.Context, s llm.Segment) llm.Segment {
+			if s == llm.Text("secret") {
+				return nil
+			}
+			return llm.Text(strings.ToUpper(string(s.(llm.Text))))
+		},
+		OnDone: func(ctx context.Context, v *llm.StreamContent) {
+			done = true
+			v.FinishReason = llm.FinishReasonStop
+		},
+	}
+
+	model := llm.Chain(&llmtest.Model{ModelName: "echo"}, llm.StreamHooksInterceptor(hooks))
+	input := &llm.Content{Role: llm.RoleUser, Parts: []llm.Segment{llm.Text("a"), llm.Text("secret"), llm.Text("b")}}
+	v := model.GenerateStream(context.Background(), nil, input)
+
+	var got []string
+	for s := range v.Stream {
+		got = append(got, string(s.(llm.Text)))
+	}
+
+	if strings.Join(got, ",") != "A,B" {
+		t.Errorf("segments = %v, want [A B]", got)
+	}
+	if !done || v.FinishReason != llm.FinishReasonStop {
+		t.Errorf("done = %v, FinishReason = %q", done, v.FinishReason)
+	}
+}
@@ -2,14 +2,61 @@ package llm
 
 import (
 	"context"
+	"time"
 )
 
 type UsageData struct {
 	InputTokens  int
 	OutputTokens int
 	TotalTokens  int
+
+	CacheReadTokens  int // Input tokens read from the prompt cache (Note: included in InputTokens)
+	CacheWriteTokens int // Input tokens written to the prompt cache (Note: included in InputTokens, except for Gemini, which writes the cache in a separate request)
+	ReasoningTokens  int // Output tokens used for reasoning (Note: included in OutputTokens)
+
+	InputModalityTokens  map[Modality]int // Input tokens per modality (Note: not available for all LLM providers)
+	OutputModalityTokens map[Modality]int // Output tokens per modality (Note: not available for all LLM providers)
+}
+
+// Add adds the token counts of other to u.
+func (u *UsageData) Add(other *UsageData) {
+	if other == nil {
+		return
+	}
+
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.TotalTokens += other.TotalTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+	u.ReasoningTokens += other.ReasoningTokens
+	u.InputModalityTokens = addModalityTokens(u.InputModalityTokens, other.InputModalityTokens)
+	u.OutputModalityTokens = addModalityTokens(u.OutputModalityTokens, other.OutputModalityTokens)
 }
 
+func addModalityTokens(dst, src map[Modality]int) map[Modality]int {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[Modality]int, len(src))
+	}
+	for m, n := range src {
+		dst[m] += n
+	}
+	return dst
+}
+
+type Modality string
+
+const (
+	ModalityText     = Modality("text")
+	ModalityImage    = Modality("image")
+	ModalityAudio    = Modality("audio")
+	ModalityVideo    = Modality("video")
+	ModalityDocument = Modality("document")
+)
+
 type Role string
 
 const (
@@ -21,6 +68,15 @@ const (
 type Content struct {
 	Role  Role      `json:"role"`
 	Parts []Segment `json:"parts"`
+
+	CacheControl *CacheControl `json:"cache_control,omitempty"` // Cache breakpoint after the last part of the content
+}
+
+// CacheControl marks a prompt cache breakpoint. The prompt up to and including the marked
+// tools, system instruction or content is cached by providers that support prompt caching,
+// and reused by later requests with the same prefix.
+type CacheControl struct {
+	TTL time.Duration `json:"ttl,omitempty"` // Minimum lifetime of the cache entry (default: provider default)
 }
 
 //go:generate go tool stringer -type=SegmentType -linecomment
@@ -71,6 +127,10 @@ type FunctionCall struct {
 func (*FunctionCall) Segment()          {}
 func (*FunctionCall) Type() SegmentType { return SegmentTypeFunctionCall }
 
+// FunctionResponse is the result of a FunctionCall, sent in a RoleFunc content.
+// Providers pair the responses with the calls of the preceding model content by ID and
+// send them in the order of the calls. A response without an ID answers the first call
+// of the same name that has no response.
 type FunctionResponse struct {
 	Name    string      `json:"name,omitempty"`
 	ID      string      `json:"id,omitempty"`
@@ -94,13 +154,47 @@ type FunctionDeclaration struct {
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
 	Schema      *Schema `json:"schema"`
+
+	CacheControl *CacheControl `json:"cache_control,omitempty"` // Cache breakpoint after the declaration
 }
 
 type ChatContext struct {
-	Contents []*Content             `json:"contents"`
-	Tools    []*FunctionDeclaration `json:"tools"`
+	Contents   []*Content             `json:"contents"`
+	Tools      []*FunctionDeclaration `json:"tools"`
+	ToolChoice *ToolChoice            `json:"tool_choice,omitempty"` // How the model should use the tools (default: ToolChoiceAuto)
 
-	SystemInstruction string `json:"system_instruction"`
+	SystemInstruction  string        `json:"system_instruction"`
+	SystemCacheControl *CacheControl `json:"system_cache_control,omitempty"` // Cache breakpoint after the system instruction
+}
+
+type ToolChoiceMode string
+
+const (
+	ToolChoiceAuto     = ToolChoiceMode("auto")     // The model decides whether to call tools
+	ToolChoiceNone     = ToolChoiceMode("none")     // The model must not call tools
+	ToolChoiceRequired = ToolChoiceMode("required") // The model must call at least one tool
+)
+
+type ToolChoice struct {
+	Mode ToolChoiceMode `json:"mode"`
+	Name string         `json:"name,omitempty"` // Name of the function that the model must call (Note: implies ToolChoiceRequired)
+}
+
+// ToolChoiceFunction returns a tool choice that forces the model to call the function.
+func ToolChoiceFunction(name string) *ToolChoice {
+	return &ToolChoice{Mode: ToolChoiceRequired, Name: name}
+}
+
+// Effective returns the mode of the tool choice, which is ToolChoiceAuto for a nil tool
+// choice and ToolChoiceRequired for a tool choice with a function name.
+func (t *ToolChoice) Effective() ToolChoiceMode {
+	switch {
+	case t == nil || t.Mode == "" && t.Name == "":
+		return ToolChoiceAuto
+	case t.Name != "":
+		return ToolChoiceRequired
+	}
+	return t.Mode
 }
 
 type FinishReason string
@@ -120,6 +214,8 @@ type StreamContent struct {
 	Content      *Content     `json:"content"`      // Only Available after Stream channel is closed
 	UsageData    *UsageData   `json:"usageData"`    // Only Available after Stream channel is closed (Note: UsageData is not available for all LLM providers)
 	FinishReason FinishReason `json:"finishReason"` // Only Available after Stream channel is closed
+	Model        string       `json:"model"`        // Only Available after Stream channel is closed (Note: Model version reported by the provider, not available for all LLM providers)
+	ResponseID   string       `json:"responseId"`   // Only Available after Stream channel is closed (Note: ResponseID is not available for all LLM providers)
 
 	Stream <-chan Segment `json:"-"` // Response Stream
 }
@@ -135,6 +231,46 @@ func (g *StreamContent) Wait() error {
 	return g.Err
 }
 
+// Forward sends the segments of g to stream and copies the result of g to v once the stream of g
+// is closed. If fn is not nil, it is called for each segment and returns the segment to send, or
+// nil to drop it. If ctx ends while a segment is sent, the remaining segments are drained
+// without being sent and v.Err is set to the error of the context unless g failed.
+// It returns the number of segments sent.
+func (g *StreamContent) Forward(ctx context.Context, stream chan<- Segment, v *StreamContent, fn func(Segment) Segment) int {
+	var sent int
+	var canceled bool
+
+	for segment := range g.Stream {
+		if canceled {
+			continue
+		}
+		if fn != nil {
+			if segment = fn(segment); segment == nil {
+				continue
+			}
+		}
+
+		select {
+		case stream <- segment:
+			sent++
+		case <-ctx.Done():
+			canceled = true
+		}
+	}
+
+	v.Err = g.Err
+	v.Content = g.Content
+	v.UsageData = g.UsageData
+	v.FinishReason = g.FinishReason
+	v.Model = g.Model
+	v.ResponseID = g.ResponseID
+	if canceled && v.Err == nil {
+		v.Err = ctx.Err()
+	}
+
+	return sent
+}
+
 type Model interface {
 	GenerateStream(ctx context.Context, chat *ChatContext, input *Content) *StreamContent
 	Close() error
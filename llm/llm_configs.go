@@ -10,6 +10,30 @@ type Config struct {
 
 	SystemInstruction     string         `json:"system_instruction,omitempty"`
 	SafetyFilterThreshold BlockThreshold `json:"filter_threshold,omitempty"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+
+	// ParallelToolCalls controls whether the model may call several tools in one turn
+	// (default: provider default). Gemini does not support disabling parallel calls.
+	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
+}
+
+// ResponseFormat constrains the output of the model to JSON.
+type ResponseFormat struct {
+	Name        string  `json:"name,omitempty"`        // Name of the output format (default: "response")
+	Description string  `json:"description,omitempty"` // Description of the output format
+	Schema      *Schema `json:"schema,omitempty"`      // Schema of the output. A nil Schema requests any JSON object.
+}
+
+// DefaultResponseFormatName is the name used for a ResponseFormat without a Name.
+const DefaultResponseFormatName = "response"
+
+// FormatName returns the name of the response format.
+func (f *ResponseFormat) FormatName() string {
+	if f == nil || f.Name == "" {
+		return DefaultResponseFormatName
+	}
+	return f.Name
 }
 
 type ThinkingConfig struct {
@@ -0,0 +1,48 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lemon-mint/coord/internal/llmtest"
+	"github.com/lemon-mint/coord/llm"
+)
+
+func TestStreamContentForward(t *testing.T) {
+	upstream := &llmtest.Model{Parts: []llm.Segment{llm.Text("a"), llm.Text("b"), llm.Text("c")}}
+	input := llm.TextContent(llm.RoleUser, "hi")
+
+	stream := make(chan llm.Segment, 3)
+	v := &llm.StreamContent{}
+	n := upstream.GenerateStream(context.Background(), nil, input).Forward(context.Background(), stream, v, func(s llm.Segment) llm.Segment {
+		if s == llm.Text("b") {
+			return nil
+		}
+		return s
+	})
+
+	if n != 2 || len(stream) != 2 {
+		t.Errorf("sent %d segments, want 2", n)
+	}
+	if v.Err != nil || v.FinishReason != llm.FinishReasonStop || v.Model != "fake" || len(v.Content.Parts) != 3 {
+		t.Errorf("unexpected result: %+v", v)
+	}
+}
+
+func TestStreamContentForwardCanceled(t *testing.T) {
+	upstream := &llmtest.Model{Parts: []llm.Segment{llm.Text("a"), llm.Text("b")}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	src := upstream.GenerateStream(context.Background(), nil, nil)
+	cancel()
+
+	// Nobody reads the stream, so Forward must not block on it.
+	v := &llm.StreamContent{}
+	if n := src.Forward(ctx, make(chan llm.Segment), v, nil); n != 0 {
+		t.Errorf("sent %d segments, want 0", n)
+	}
+	if !errors.Is(v.Err, context.Canceled) {
+		t.Errorf("err = %v, want %v", v.Err, context.Canceled)
+	}
+}
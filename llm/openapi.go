@@ -1,5 +1,10 @@
 package llm
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 type OpenAPIType string
 
 const (
@@ -12,7 +17,7 @@ const (
 )
 
 type Schema struct {
-	Type  OpenAPIType `json:"type"`
+	Type  OpenAPIType `json:"type,omitempty"`
 	Title string      `json:"title,omitempty"`
 
 	Description string             `json:"description,omitempty"`
@@ -24,4 +29,61 @@ type Schema struct {
 	Format   string        `json:"format,omitempty"`
 	Enum     []interface{} `json:"enum,omitempty"`
 	Default  interface{}   `json:"default,omitempty"`
+
+	AnyOf []*Schema `json:"anyOf,omitempty"` // The value matches at least one of the schemas
+	OneOf []*Schema `json:"oneOf,omitempty"` // The value matches exactly one of the schemas
+
+	Ref  string             `json:"$ref,omitempty"`  // Reference to a definition of the root schema ("#/$defs/<name>") or to the root schema ("#")
+	Defs map[string]*Schema `json:"$defs,omitempty"` // Shared definitions (Note: only used on the root schema)
+
+	Minimum   *float64 `json:"minimum,omitempty"`   // Minimum of a number or integer (inclusive)
+	Maximum   *float64 `json:"maximum,omitempty"`   // Maximum of a number or integer (inclusive)
+	MinLength *int     `json:"minLength,omitempty"` // Minimum length of a string
+	MaxLength *int     `json:"maxLength,omitempty"` // Maximum length of a string
+	Pattern   string   `json:"pattern,omitempty"`   // Regular expression that a string matches
+	MinItems  *int     `json:"minItems,omitempty"`  // Minimum number of items of an array
+	MaxItems  *int     `json:"maxItems,omitempty"`  // Maximum number of items of an array
+
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties,omitempty"` // Properties of an object that are not listed in Properties (Note: nil allows any property)
+}
+
+// AdditionalProperties is encoded as a boolean, or as the schema of the values if Schema is set.
+type AdditionalProperties struct {
+	Allowed bool    // Whether properties not listed in Properties are allowed
+	Schema  *Schema // Schema of the additional property values (Note: implies Allowed)
+}
+
+func (a AdditionalProperties) MarshalJSON() ([]byte, error) {
+	if a.Schema != nil {
+		return json.Marshal(a.Schema)
+	}
+	return json.Marshal(a.Allowed)
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		a.Schema = nil
+		return nil
+	}
+
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// Resolve returns the schema referenced by ref within the root schema,
+// or nil if the reference can not be resolved.
+func (s *Schema) Resolve(ref string) *Schema {
+	if s == nil {
+		return nil
+	}
+
+	if ref == "#" {
+		return s
+	}
+
+	if name, ok := strings.CutPrefix(ref, "#/$defs/"); ok {
+		return s.Defs[name]
+	}
+
+	return nil
 }
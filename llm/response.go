@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"context"
+	"maps"
+	"strings"
+)
+
+// Response is the complete result of a generation.
+// Its content is a copy that is not shared with the stream it was built from.
+type Response struct {
+	Content      *Content     `json:"content"`
+	UsageData    *UsageData   `json:"usageData"` // Note: UsageData is not available for all LLM providers
+	FinishReason FinishReason `json:"finishReason"`
+	Model        string       `json:"model"`      // Model version reported by the provider, or the name of the model
+	ResponseID   string       `json:"responseId"` // Note: ResponseID is not available for all LLM providers
+}
+
+// Text returns the text content of the response.
+func (r Response) Text() string {
+	if r.Content == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for i := range r.Content.Parts {
+		if r.Content.Parts[i].Type() == SegmentTypeText {
+			sb.WriteString(string(r.Content.Parts[i].(Text)))
+		}
+	}
+
+	return sb.String()
+}
+
+// Response drains the stream and returns its result.
+func (g *StreamContent) Response() (Response, error) {
+	if err := g.Wait(); err != nil {
+		return Response{}, err
+	}
+
+	r := Response{
+		FinishReason: g.FinishReason,
+		Model:        g.Model,
+		ResponseID:   g.ResponseID,
+	}
+
+	if g.Content != nil {
+		r.Content = &Content{
+			Role:  g.Content.Role,
+			Parts: make([]Segment, len(g.Content.Parts)),
+		}
+		for i := range g.Content.Parts {
+			r.Content.Parts[i] = cloneSegment(g.Content.Parts[i])
+		}
+	}
+
+	if g.UsageData != nil {
+		usage := *g.UsageData
+		usage.InputModalityTokens = maps.Clone(usage.InputModalityTokens)
+		usage.OutputModalityTokens = maps.Clone(usage.OutputModalityTokens)
+		r.UsageData = &usage
+	}
+
+	return r, nil
+}
+
+// cloneSegment returns a deep copy of the segment.
+func cloneSegment(s Segment) Segment {
+	switch v := s.(type) {
+	case *InlineData:
+		c := *v
+		c.Data = append([]byte(nil), v.Data...)
+		return &c
+	case *FileData:
+		c := *v
+		return &c
+	case *FunctionCall:
+		c := *v
+		if v.Args != nil {
+			c.Args = cloneValue(v.Args).(map[string]interface{})
+		}
+		return &c
+	case *FunctionResponse:
+		c := *v
+		c.Content = cloneValue(v.Content)
+		return &c
+	case *ThinkingBlock:
+		c := *v
+		return &c
+	}
+	return s
+}
+
+// cloneValue returns a deep copy of the maps and slices of a JSON-like value.
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			c[k] = cloneValue(e)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = cloneValue(e)
+		}
+		return c
+	}
+	return v
+}
+
+// GenerateModel is implemented by models with a native non-streaming API.
+type GenerateModel interface {
+	Model
+	Generate(ctx context.Context, chat *ChatContext, input *Content) (Response, error)
+}
+
+// Generate generates a response and blocks until it is complete.
+// If the model does not implement GenerateModel, the response is collected from GenerateStream.
+func Generate(ctx context.Context, m Model, chat *ChatContext, input *Content) (Response, error) {
+	if gm, ok := m.(GenerateModel); ok {
+		return gm.Generate(ctx, chat, input)
+	}
+
+	r, err := m.GenerateStream(ctx, chat, input).Response()
+	if err != nil {
+		return Response{}, err
+	}
+
+	if r.Model == "" {
+		r.Model = m.Name()
+	}
+
+	return r, nil
+}
+
+type generateModel struct {
+	Model
+}
+
+func (g generateModel) Generate(ctx context.Context, chat *ChatContext, input *Content) (Response, error) {
+	return Generate(ctx, g.Model, chat, input)
+}
+
+// WithGenerate returns the model as a GenerateModel.
+// Models that do not implement GenerateModel are adapted with Generate.
+func WithGenerate(m Model) GenerateModel {
+	if gm, ok := m.(GenerateModel); ok {
+		return gm
+	}
+
+	return generateModel{m}
+}
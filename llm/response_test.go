@@ -0,0 +1,102 @@
+package llm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+type staticModel struct{}
+
+func (staticModel) GenerateStream(ctx context.Context, chat *llm.ChatContext, input *llm.Content) *llm.StreamContent {
+	stream := make(chan llm.Segment, 2)
+	v := &llm.StreamContent{
+		Content: &llm.Content{Role: llm.RoleModel},
+		Stream:  stream,
+	}
+
+	go func() {
+		defer close(stream)
+		for _, t := range []llm.Text{"Hello, ", "World!"} {
+			v.Content.Parts = append(v.Content.Parts, t)
+			stream <- t
+		}
+		v.FinishReason = llm.FinishReasonStop
+		v.ResponseID = "resp-1"
+	}()
+
+	return v
+}
+
+func (staticModel) Close() error { return nil }
+func (staticModel) Name() string { return "static" }
+
+func TestGenerate(t *testing.T) {
+	r, err := llm.WithGenerate(staticModel{}).Generate(context.Background(), nil, llm.TextContent(llm.RoleUser, "Hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Text() != "Hello, World!" {
+		t.Errorf("unexpected text: %q", r.Text())
+	}
+	if r.FinishReason != llm.FinishReasonStop || r.ResponseID != "resp-1" || r.Model != "static" {
+		t.Errorf("unexpected response: %+v", r)
+	}
+}
+
+func TestUsageDataAdd(t *testing.T) {
+	usage := &llm.UsageData{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}
+	usage.Add(&llm.UsageData{
+		InputTokens:         20,
+		OutputTokens:        8,
+		TotalTokens:         28,
+		CacheReadTokens:     12,
+		ReasoningTokens:     3,
+		InputModalityTokens: map[llm.Modality]int{llm.ModalityText: 15, llm.ModalityImage: 5},
+	})
+	usage.Add(&llm.UsageData{InputModalityTokens: map[llm.Modality]int{llm.ModalityText: 1}})
+	usage.Add(nil)
+
+	if usage.InputTokens != 30 || usage.OutputTokens != 13 || usage.TotalTokens != 43 || usage.CacheReadTokens != 12 || usage.ReasoningTokens != 3 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+	if usage.InputModalityTokens[llm.ModalityText] != 16 || usage.InputModalityTokens[llm.ModalityImage] != 5 {
+		t.Errorf("unexpected modality tokens: %v", usage.InputModalityTokens)
+	}
+}
+
+func TestResponseCopy(t *testing.T) {
+	stream := make(chan llm.Segment)
+	close(stream)
+
+	call := &llm.FunctionCall{Name: "f", Args: map[string]interface{}{"list": []interface{}{"a"}}}
+	data := &llm.InlineData{MIMEType: "image/png", Data: []byte{1}}
+	v := &llm.StreamContent{
+		Content:   &llm.Content{Role: llm.RoleModel, Parts: []llm.Segment{call, data}},
+		UsageData: &llm.UsageData{InputModalityTokens: map[llm.Modality]int{llm.ModalityText: 1}},
+		Stream:    stream,
+	}
+
+	r, err := v.Response()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call.Name = "g"
+	call.Args["list"].([]interface{})[0] = "b"
+	data.Data[0] = 2
+	v.UsageData.InputModalityTokens[llm.ModalityText] = 2
+
+	got := r.Content.Parts[0].(*llm.FunctionCall)
+	if got.Name != "f" || got.Args["list"].([]interface{})[0] != "a" {
+		t.Errorf("function call shared with the stream: %+v", got)
+	}
+	if r.Content.Parts[1].(*llm.InlineData).Data[0] != 1 {
+		t.Error("inline data shared with the stream")
+	}
+	if r.UsageData.InputModalityTokens[llm.ModalityText] != 1 {
+		t.Error("modality tokens shared with the stream")
+	}
+}
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Coerce returns a copy of the decoded value with the conversions that are safe for the
+// schema applied: strings holding numbers or booleans become numbers and booleans, numbers
+// and booleans become strings, and numbers of any Go type become float64 like in values
+// decoded by encoding/json. Values that can not be converted are returned unchanged, so
+// the result should still be checked with Validate. References are resolved against s.
+func (s *Schema) Coerce(v interface{}) interface{} {
+	return s.coerce(s, v, 0)
+}
+
+// maxCoerceDepth bounds the resolution of references that do not consume any input.
+const maxCoerceDepth = 64
+
+func (s *Schema) coerce(root *Schema, v interface{}, depth int) interface{} {
+	if s == nil || v == nil || depth > maxCoerceDepth {
+		return v
+	}
+
+	if s.Ref != "" {
+		return root.Resolve(s.Ref).coerce(root, v, depth+1)
+	}
+
+	switch s.Type {
+	case OpenAPITypeString:
+		switch x := v.(type) {
+		case bool:
+			return strconv.FormatBool(x)
+		default:
+			if n, ok := toFloat(x); ok {
+				return strconv.FormatFloat(n, 'f', -1, 64)
+			}
+		}
+	case OpenAPITypeNumber, OpenAPITypeInteger:
+		if x, ok := v.(string); ok {
+			n, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+			if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+				return v
+			}
+			if s.Type == OpenAPITypeInteger && n != math.Trunc(n) {
+				return v
+			}
+			return n
+		}
+		if n, ok := toFloat(v); ok {
+			return n
+		}
+	case OpenAPITypeBoolean:
+		if x, ok := v.(string); ok {
+			switch strings.ToLower(strings.TrimSpace(x)) {
+			case "true":
+				return true
+			case "false":
+				return false
+			}
+		}
+	case OpenAPITypeArray:
+		if items, ok := v.([]interface{}); ok {
+			out := make([]interface{}, len(items))
+			for i := range items {
+				out[i] = s.Items.coerce(root, items[i], depth+1)
+			}
+			return out
+		}
+	case OpenAPITypeObject:
+		if obj, ok := v.(map[string]interface{}); ok {
+			out := make(map[string]interface{}, len(obj))
+			for k, pv := range obj {
+				ps, ok := s.Properties[k]
+				if !ok && s.AdditionalProperties != nil {
+					ps = s.AdditionalProperties.Schema
+				}
+				out[k] = ps.coerce(root, pv, depth+1)
+			}
+			return out
+		}
+	case "":
+		// A union is coerced to the first option the value matches after coercion.
+		for _, options := range [][]*Schema{s.AnyOf, s.OneOf} {
+			for _, o := range options {
+				if o.validate(root, "$", v, depth+1) == nil {
+					return v
+				}
+			}
+			for _, o := range options {
+				if c := o.coerce(root, v, depth+1); o.validate(root, "$", c, depth+1) == nil {
+					return c
+				}
+			}
+		}
+		if n, ok := toFloat(v); ok {
+			return n
+		}
+	}
+
+	return v
+}
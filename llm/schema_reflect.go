@@ -0,0 +1,280 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	jsonRawType    = reflect.TypeOf(json.RawMessage(nil))
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	byteSliceType  = reflect.TypeOf([]byte(nil))
+	anyType        = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// SchemaFor returns the schema of the JSON encoding of T. See SchemaOf.
+func SchemaFor[T any]() (*Schema, error) {
+	return SchemaOf(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// SchemaOf returns the schema of the JSON encoding of the type.
+//
+// Struct fields are named by their json tags and are required unless tagged omitempty.
+// The description and enum tags set the description and the comma-separated allowed
+// values of a field. Pointers are nullable. Maps are objects whose additional properties
+// have the schema of the map values. A struct type that contains itself is added to the
+// definitions of the root schema and referenced with $ref.
+func SchemaOf(t reflect.Type) (*Schema, error) {
+	if t == nil {
+		return nil, fmt.Errorf("llm: nil type")
+	}
+
+	b := &schemaBuilder{
+		cache:    make(map[reflect.Type]*Schema),
+		building: make(map[reflect.Type]bool),
+		defs:     make(map[reflect.Type]string),
+	}
+
+	root, err := b.build(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b.defs) > 0 {
+		// The root is copied, so that a recursive root type does not contain itself.
+		defs := make(map[string]*Schema, len(b.defs))
+		for dt, name := range b.defs {
+			defs[name] = b.cache[dt]
+		}
+		r := *root
+		r.Defs = defs
+		root = &r
+	}
+
+	return root, nil
+}
+
+type schemaBuilder struct {
+	cache    map[reflect.Type]*Schema
+	building map[reflect.Type]bool   // struct types whose fields are being built
+	defs     map[reflect.Type]string // recursive struct types and their definition names
+}
+
+// ref returns a reference to the definition of the recursive struct type.
+func (b *schemaBuilder) ref(t reflect.Type) *Schema {
+	name, ok := b.defs[t]
+	if !ok {
+		name = t.Name()
+		if name == "" {
+			name = "def"
+		}
+		for _, n := range b.defs {
+			if n == name {
+				name += strconv.Itoa(len(b.defs))
+				break
+			}
+		}
+		b.defs[t] = name
+	}
+
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+func (b *schemaBuilder) build(t reflect.Type) (*Schema, error) {
+	switch t {
+	case timeType:
+		return &Schema{Type: OpenAPITypeString, Format: "date-time"}, nil
+	case jsonRawType, anyType:
+		return &Schema{}, nil
+	case jsonNumberType:
+		return &Schema{Type: OpenAPITypeNumber}, nil
+	case byteSliceType:
+		return &Schema{Type: OpenAPITypeString, Format: "byte"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: OpenAPITypeBoolean}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: OpenAPITypeInteger}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: OpenAPITypeNumber}, nil
+	case reflect.String:
+		return &Schema{Type: OpenAPITypeString}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Pointer:
+		if b.building[t.Elem()] {
+			ref := b.ref(t.Elem())
+			ref.Nullable = true
+			return ref, nil
+		}
+		s, err := b.build(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		nullable := *s
+		nullable.Nullable = true
+		return &nullable, nil
+	case reflect.Slice, reflect.Array:
+		items, err := b.build(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: OpenAPITypeArray, Items: items}, nil
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return nil, fmt.Errorf("llm: unsupported map key type %s", t.Key())
+		}
+		values, err := b.build(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{
+			Type:                 OpenAPITypeObject,
+			AdditionalProperties: &AdditionalProperties{Allowed: true, Schema: values},
+		}, nil
+	case reflect.Struct:
+		return b.buildStruct(t)
+	}
+
+	return nil, fmt.Errorf("llm: unsupported type %s", t)
+}
+
+func (b *schemaBuilder) buildStruct(t reflect.Type) (*Schema, error) {
+	if b.building[t] {
+		return b.ref(t), nil
+	}
+	if s, ok := b.cache[t]; ok {
+		return s, nil
+	}
+
+	s := &Schema{
+		Type:       OpenAPITypeObject,
+		Properties: make(map[string]*Schema),
+	}
+	b.cache[t] = s
+
+	b.building[t] = true
+	defer delete(b.building, t)
+
+	if err := b.addFields(s, t); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// addFields adds the fields of the struct to the schema, flattening embedded structs like encoding/json.
+func (b *schemaBuilder) addFields(s *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := b.addFields(s, ft); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		var fs *Schema
+		if hasTagOption(opts, "string") {
+			fs = &Schema{Type: OpenAPITypeString}
+		} else {
+			var err error
+			fs, err = b.build(f.Type)
+			if err != nil {
+				return fmt.Errorf("llm: field %s.%s: %w", t.Name(), f.Name, err)
+			}
+		}
+
+		description, hasDescription := f.Tag.Lookup("description")
+		enum, hasEnum := f.Tag.Lookup("enum")
+		if hasDescription || hasEnum {
+			// Copy the schema, which may be shared with other fields of the same type.
+			annotated := *fs
+			fs = &annotated
+		}
+		if hasDescription {
+			fs.Description = description
+		}
+		if hasEnum {
+			values, err := parseEnum(enum, fs.Type)
+			if err != nil {
+				return fmt.Errorf("llm: field %s.%s: %w", t.Name(), f.Name, err)
+			}
+			fs.Enum = values
+		}
+
+		s.Properties[name] = fs
+		if !hasTagOption(opts, "omitempty") && !hasTagOption(opts, "omitzero") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return nil
+}
+
+func hasTagOption(opts string, option string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func parseEnum(tag string, typ OpenAPIType) ([]interface{}, error) {
+	parts := strings.Split(tag, ",")
+	values := make([]interface{}, len(parts))
+	for i := range parts {
+		p := strings.TrimSpace(parts[i])
+		switch typ {
+		case OpenAPITypeInteger:
+			n, err := strconv.ParseInt(p, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid integer enum value %q", p)
+			}
+			values[i] = n
+		case OpenAPITypeNumber:
+			n, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number enum value %q", p)
+			}
+			values[i] = n
+		default:
+			values[i] = p
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,89 @@
+package llm_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+type weatherRequest struct {
+	Location string   `json:"location" description:"City name"`
+	Unit     string   `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	Days     *int     `json:"days,omitempty"`
+	Tags     []string `json:"tags"`
+	internal int
+}
+
+type treeNode struct {
+	Name     string      `json:"name"`
+	Children []*treeNode `json:"children,omitempty"`
+}
+
+type listNode struct {
+	Value int       `json:"value"`
+	Next  *listNode `json:"next"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	s, err := llm.SchemaFor[weatherRequest]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Type != llm.OpenAPITypeObject || len(s.Properties) != 4 {
+		t.Fatalf("unexpected schema: %+v", s)
+	}
+	if s.Properties["location"].Description != "City name" {
+		t.Errorf("missing description: %+v", s.Properties["location"])
+	}
+	if len(s.Properties["unit"].Enum) != 2 {
+		t.Errorf("missing enum: %+v", s.Properties["unit"])
+	}
+	if p := s.Properties["days"]; p.Type != llm.OpenAPITypeInteger || !p.Nullable {
+		t.Errorf("expected a nullable integer: %+v", p)
+	}
+	if p := s.Properties["tags"]; p.Type != llm.OpenAPITypeArray || p.Items.Type != llm.OpenAPITypeString {
+		t.Errorf("expected a string array: %+v", p)
+	}
+	if len(s.Required) != 2 {
+		t.Errorf("unexpected required properties: %v", s.Required)
+	}
+}
+
+func TestSchemaForRecursive(t *testing.T) {
+	s, err := llm.SchemaFor[treeNode]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := s.Properties["children"].Items.Ref
+	if ref != "#/$defs/treeNode" || s.Resolve(ref) == nil {
+		t.Errorf("expected a reference to the definition, got %q", ref)
+	}
+	if _, err := json.Marshal(s); err != nil {
+		t.Errorf("failed to marshal the schema: %v", err)
+	}
+}
+
+func TestSchemaForRecursivePointer(t *testing.T) {
+	s, err := llm.SchemaFor[listNode]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p := s.Properties["next"]; p.Ref != "#/$defs/listNode" || !p.Nullable {
+		t.Errorf("expected a nullable reference: %+v", p)
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(`{"value": 1, "next": {"value": 2, "next": null}}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate(v); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+	if err := s.Validate(map[string]any{"value": 1, "next": map[string]any{"next": nil}}); err == nil {
+		t.Error("expected an error for a node without a value")
+	}
+}
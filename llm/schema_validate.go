@@ -0,0 +1,285 @@
+package llm
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ValidationError reports a value that does not match a schema.
+type ValidationError struct {
+	Path    string `json:"path"`    // Path of the invalid value (e.g. "$.items[0].name")
+	Message string `json:"message"` // Reason the value is invalid
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("llm: invalid value at %s: %s", e.Path, e.Message)
+}
+
+// Validate checks a decoded JSON value (as produced by json.Unmarshal into an interface{})
+// against the schema. Properties that are not declared in the schema are allowed unless
+// AdditionalProperties forbids them. A null value of a property that is not required is
+// treated as an absent property. References are resolved against s.
+func (s *Schema) Validate(v interface{}) error {
+	return s.validate(s, "$", v, 0)
+}
+
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, float32, int, int64, int32, uint, uint64, uint32:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func enumContains(enum []interface{}, v interface{}) bool {
+	for i := range enum {
+		if a, ok := toFloat(enum[i]); ok {
+			if b, ok := toFloat(v); ok && a == b {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(enum[i], v) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxValidateDepth bounds the nesting of schemas, including references that do not consume any input.
+const maxValidateDepth = 64
+
+func (s *Schema) validate(root *Schema, path string, v interface{}, depth int) error {
+	if s == nil {
+		return nil
+	}
+
+	if depth > maxValidateDepth {
+		return &ValidationError{Path: path, Message: "schema nesting exceeds the maximum depth of " + strconv.Itoa(maxValidateDepth)}
+	}
+
+	if v == nil && s.Nullable {
+		return nil
+	}
+
+	if s.Ref != "" {
+		ref := root.Resolve(s.Ref)
+		if ref == nil {
+			return &ValidationError{Path: path, Message: "unresolved reference " + strconv.Quote(s.Ref)}
+		}
+		if err := ref.validate(root, path, v, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if v == nil {
+		if s.Type == "" {
+			return s.validateUnion(root, path, v, depth)
+		}
+		return &ValidationError{Path: path, Message: "expected " + string(s.Type) + ", got null"}
+	}
+
+	mismatch := func() error {
+		return &ValidationError{Path: path, Message: "expected " + string(s.Type) + ", got " + jsonTypeName(v)}
+	}
+
+	switch s.Type {
+	case OpenAPITypeString:
+		if _, ok := v.(string); !ok {
+			return mismatch()
+		}
+	case OpenAPITypeNumber:
+		if _, ok := toFloat(v); !ok {
+			return mismatch()
+		}
+	case OpenAPITypeInteger:
+		n, ok := toFloat(v)
+		if !ok || n != math.Trunc(n) {
+			return mismatch()
+		}
+	case OpenAPITypeBoolean:
+		if _, ok := v.(bool); !ok {
+			return mismatch()
+		}
+	case OpenAPITypeArray:
+		if _, ok := v.([]interface{}); !ok {
+			return mismatch()
+		}
+	case OpenAPITypeObject:
+		if _, ok := v.(map[string]interface{}); !ok {
+			return mismatch()
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		if err := s.validateString(path, v); err != nil {
+			return err
+		}
+	case []interface{}:
+		if err := s.validateArray(root, path, v, depth); err != nil {
+			return err
+		}
+	case map[string]interface{}:
+		if err := s.validateObject(root, path, v, depth); err != nil {
+			return err
+		}
+	default:
+		if n, ok := toFloat(v); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("value %v is less than the minimum %v", v, *s.Minimum)}
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("value %v is greater than the maximum %v", v, *s.Maximum)}
+			}
+		}
+	}
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, v) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("value %v is not one of %v", v, s.Enum)}
+	}
+
+	return s.validateUnion(root, path, v, depth)
+}
+
+func (s *Schema) validateString(path string, v string) error {
+	n := utf8.RuneCountInString(v)
+	if s.MinLength != nil && n < *s.MinLength {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("length %d is less than the minimum length %d", n, *s.MinLength)}
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("length %d is greater than the maximum length %d", n, *s.MaxLength)}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return &ValidationError{Path: path, Message: "invalid pattern " + strconv.Quote(s.Pattern)}
+		}
+		if !re.MatchString(v) {
+			return &ValidationError{Path: path, Message: "value does not match the pattern " + strconv.Quote(s.Pattern)}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(root *Schema, path string, items []interface{}, depth int) error {
+	if s.MinItems != nil && len(items) < *s.MinItems {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("%d items is less than the minimum of %d", len(items), *s.MinItems)}
+	}
+	if s.MaxItems != nil && len(items) > *s.MaxItems {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("%d items is greater than the maximum of %d", len(items), *s.MaxItems)}
+	}
+	for i := range items {
+		if err := s.Items.validate(root, path+"["+strconv.Itoa(i)+"]", items[i], depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(root *Schema, path string, obj map[string]interface{}, depth int) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return &ValidationError{Path: path, Message: "missing required property " + strconv.Quote(name)}
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// Optional properties are nullable in strict structured outputs, so null is treated as absent.
+		if obj[name] == nil && !slices.Contains(s.Required, name) {
+			continue
+		}
+
+		if ps, ok := s.Properties[name]; ok {
+			if err := ps.validate(root, path+"."+name, obj[name], depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ap := s.AdditionalProperties
+		if ap == nil {
+			continue
+		}
+		if !ap.Allowed && ap.Schema == nil {
+			return &ValidationError{Path: path, Message: "unexpected property " + strconv.Quote(name)}
+		}
+		if err := ap.Schema.validate(root, path+"."+name, obj[name], depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateUnion(root *Schema, path string, v interface{}, depth int) error {
+	if len(s.AnyOf) > 0 {
+		var matched bool
+		for _, o := range s.AnyOf {
+			if o.validate(root, path, v, depth+1) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &ValidationError{Path: path, Message: "value does not match any of the options"}
+		}
+	}
+
+	if len(s.OneOf) > 0 {
+		var matched int
+		for _, o := range s.OneOf {
+			if o.validate(root, path, v, depth+1) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("value matches %d of the options, expected exactly one", matched)}
+		}
+	}
+
+	return nil
+}
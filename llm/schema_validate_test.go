@@ -0,0 +1,147 @@
+package llm_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	schema := &llm.Schema{
+		Type: llm.OpenAPITypeObject,
+		Properties: map[string]*llm.Schema{
+			"name": {Type: llm.OpenAPITypeString},
+			"age":  {Type: llm.OpenAPITypeInteger},
+			"tags": {Type: llm.OpenAPITypeArray, Items: &llm.Schema{Type: llm.OpenAPITypeString, Enum: []interface{}{"a", "b"}}},
+		},
+		Required: []string{"name"},
+	}
+
+	tests := []struct {
+		input string
+		path  string
+	}{
+		{`{"name": "x", "age": 3, "tags": ["a"]}`, ""},
+		{`{"age": 3}`, "$"},
+		{`{"name": "x", "age": 3.5}`, "$.age"},
+		{`{"name": "x", "tags": ["a", "c"]}`, "$.tags[1]"},
+		{`{"name": "x", "age": null}`, ""},
+		{`{"name": null}`, "$.name"},
+	}
+
+	for _, tt := range tests {
+		var v interface{}
+		if err := json.Unmarshal([]byte(tt.input), &v); err != nil {
+			t.Fatal(err)
+		}
+
+		err := schema.Validate(v)
+		if tt.path == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.input, err)
+			}
+			continue
+		}
+
+		verr, ok := err.(*llm.ValidationError)
+		if !ok || verr.Path != tt.path {
+			t.Errorf("%s: expected an error at %s, got %v", tt.input, tt.path, err)
+		}
+	}
+}
+
+func TestSchemaValidateConstraints(t *testing.T) {
+	minimum, maximum := 1.0, 10.0
+	minLength, maxItems := 2, 2
+
+	schema := &llm.Schema{
+		Type: llm.OpenAPITypeObject,
+		Properties: map[string]*llm.Schema{
+			"count": {Type: llm.OpenAPITypeInteger, Minimum: &minimum, Maximum: &maximum},
+			"code":  {Type: llm.OpenAPITypeString, MinLength: &minLength, Pattern: "^[A-Z]+$"},
+			"tags":  {Type: llm.OpenAPITypeArray, MaxItems: &maxItems, Items: &llm.Schema{Type: llm.OpenAPITypeString}},
+			"id": {AnyOf: []*llm.Schema{
+				{Type: llm.OpenAPITypeString},
+				{Type: llm.OpenAPITypeInteger},
+			}},
+			"node": {Ref: "#/$defs/node"},
+		},
+		AdditionalProperties: &llm.AdditionalProperties{Allowed: false},
+		Defs: map[string]*llm.Schema{
+			"node": {
+				Type: llm.OpenAPITypeObject,
+				Properties: map[string]*llm.Schema{
+					"next": {Ref: "#/$defs/node", Nullable: true},
+				},
+				Required: []string{"next"},
+			},
+		},
+	}
+
+	tests := []struct {
+		input string
+		path  string
+	}{
+		{`{"count": 3, "code": "AB", "tags": ["a"], "id": 1, "node": {"next": {"next": null}}}`, ""},
+		{`{"count": 0}`, "$.count"},
+		{`{"count": 11}`, "$.count"},
+		{`{"code": "A"}`, "$.code"},
+		{`{"code": "ab"}`, "$.code"},
+		{`{"tags": ["a", "b", "c"]}`, "$.tags"},
+		{`{"id": true}`, "$.id"},
+		{`{"node": {"next": {}}}`, "$.node.next"},
+		{`{"other": 1}`, "$"},
+	}
+
+	for _, tt := range tests {
+		var v interface{}
+		if err := json.Unmarshal([]byte(tt.input), &v); err != nil {
+			t.Fatal(err)
+		}
+
+		err := schema.Validate(v)
+		if tt.path == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.input, err)
+			}
+			continue
+		}
+
+		verr, ok := err.(*llm.ValidationError)
+		if !ok || verr.Path != tt.path {
+			t.Errorf("%s: expected an error at %s, got %v", tt.input, tt.path, err)
+		}
+	}
+}
+
+func TestAdditionalPropertiesJSON(t *testing.T) {
+	var s llm.Schema
+	if err := json.Unmarshal([]byte(`{"type": "object", "additionalProperties": {"type": "string"}}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.AdditionalProperties == nil || !s.AdditionalProperties.Allowed || s.AdditionalProperties.Schema.Type != llm.OpenAPITypeString {
+		t.Errorf("unexpected additionalProperties: %+v", s.AdditionalProperties)
+	}
+
+	s.AdditionalProperties = &llm.AdditionalProperties{Allowed: false}
+	data, err := json.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"type":"object","additionalProperties":false}` {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
+
+func TestSchemaValidateRefCycle(t *testing.T) {
+	var s llm.Schema
+	if err := json.Unmarshal([]byte(`{"$ref": "#/$defs/A", "$defs": {"A": {"$ref": "#/$defs/A"}}}`), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.Validate(map[string]any{})
+	if _, ok := err.(*llm.ValidationError); !ok {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Package agent runs a model in a loop that executes the tools it calls.
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+var ErrMaxIterations = errors.New("agent: maximum number of iterations reached")
+
+const defaultMaxIterations = 10
+
+type Config struct {
+	MaxIterations int // Maximum number of model calls in a run (default: 10)
+	MaxParallel   int // Maximum number of tools executed concurrently (0 for no limit)
+}
+
+type Agent struct {
+	model  llm.Model
+	tools  *Registry
+	config Config
+}
+
+func New(model llm.Model, tools *Registry, config *Config) *Agent {
+	if config == nil {
+		config = &Config{}
+	}
+	if tools == nil {
+		tools = &Registry{}
+	}
+
+	a := &Agent{
+		model:  model,
+		tools:  tools,
+		config: *config,
+	}
+	if a.config.MaxIterations <= 0 {
+		a.config.MaxIterations = defaultMaxIterations
+	}
+
+	return a
+}
+
+type Result struct {
+	Transcript []*llm.Content `json:"transcript"` // Contents of the chat followed by the contents added in the run
+	Response   llm.Response   `json:"response"`   // Last response of the model
+	UsageData  *llm.UsageData `json:"usageData"`  // Sum of the usage of all model calls (Note: nil if not reported by the provider)
+	Iterations int            `json:"iterations"` // Number of model calls
+}
+
+func functionCalls(c *llm.Content) []*llm.FunctionCall {
+	if c == nil {
+		return nil
+	}
+
+	var calls []*llm.FunctionCall
+	for i := range c.Parts {
+		if fc, ok := c.Parts[i].(*llm.FunctionCall); ok {
+			calls = append(calls, fc)
+		}
+	}
+	return calls
+}
+
+// execute runs the calls concurrently and returns the responses in call order.
+func (a *Agent) execute(ctx context.Context, calls []*llm.FunctionCall) *llm.Content {
+	responses := make([]llm.Segment, len(calls))
+
+	var sem chan struct{}
+	if a.config.MaxParallel > 0 {
+		sem = make(chan struct{}, a.config.MaxParallel)
+	}
+
+	var wg sync.WaitGroup
+	for i := range calls {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+			responses[i] = a.tools.Call(ctx, calls[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return &llm.Content{
+		Role:  llm.RoleFunc,
+		Parts: responses,
+	}
+}
+
+// Run generates responses until the model answers without calling a tool.
+// The tools of the registry are added to the tools of the chat. If the model is
+// still calling tools after MaxIterations calls, the result is returned with ErrMaxIterations.
+// A tool choice that requires a tool call only applies to the first model call, so that the
+// model can answer once it has the tool responses.
+func (a *Agent) Run(ctx context.Context, chat *llm.ChatContext, input *llm.Content) (*Result, error) {
+	if chat == nil {
+		chat = &llm.ChatContext{}
+	}
+
+	turn := *chat
+	turn.Contents = append([]*llm.Content(nil), chat.Contents...)
+	turn.Tools = append(append([]*llm.FunctionDeclaration(nil), chat.Tools...), a.tools.Declarations()...)
+
+	result := &Result{}
+	for result.Iterations < a.config.MaxIterations {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		r, err := llm.Generate(ctx, a.model, &turn, input)
+		result.Iterations++
+		if err != nil {
+			result.Transcript = turn.Contents
+			return result, err
+		}
+
+		result.Response = r
+		if r.UsageData != nil {
+			if result.UsageData == nil {
+				result.UsageData = &llm.UsageData{}
+			}
+			result.UsageData.Add(r.UsageData)
+		}
+
+		turn.Contents = append(turn.Contents, input)
+		if r.Content != nil {
+			turn.Contents = append(turn.Contents, r.Content)
+		}
+
+		calls := functionCalls(r.Content)
+		if len(calls) == 0 {
+			result.Transcript = turn.Contents
+			return result, nil
+		}
+
+		input = a.execute(ctx, calls)
+
+		if tc := turn.ToolChoice; tc != nil && (tc.Mode == llm.ToolChoiceRequired || tc.Name != "") {
+			turn.ToolChoice = nil
+		}
+	}
+
+	// The responses of the last tool calls are part of the transcript,
+	// so that the run can be continued with it.
+	result.Transcript = append(turn.Contents, input)
+	return result, ErrMaxIterations
+}
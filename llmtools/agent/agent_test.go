@@ -0,0 +1,177 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/llmtools/agent"
+)
+
+// scriptedModel calls get_weather twice in parallel on the first turn and answers on the second.
+type scriptedModel struct{}
+
+func (scriptedModel) GenerateStream(ctx context.Context, chat *llm.ChatContext, input *llm.Content) *llm.StreamContent {
+	stream := make(chan llm.Segment)
+	close(stream)
+
+	content := &llm.Content{Role: llm.RoleModel}
+	if input.Role == llm.RoleFunc {
+		content.Parts = []llm.Segment{llm.Text("done")}
+	} else {
+		content.Parts = []llm.Segment{
+			&llm.FunctionCall{ID: "1", Name: "get_weather", Args: map[string]interface{}{"location": "Seoul"}},
+			&llm.FunctionCall{ID: "2", Name: "get_weather", Args: map[string]interface{}{"location": "Nowhere"}},
+		}
+	}
+
+	return &llm.StreamContent{
+		Content:      content,
+		Stream:       stream,
+		FinishReason: llm.FinishReasonStop,
+		UsageData:    &llm.UsageData{InputTokens: 1, OutputTokens: 1, TotalTokens: 2},
+	}
+}
+
+func (scriptedModel) Close() error { return nil }
+func (scriptedModel) Name() string { return "scripted" }
+
+type weatherArgs struct {
+	Location string `json:"location"`
+}
+
+func TestAgentRun(t *testing.T) {
+	tool, err := agent.NewTool("get_weather", "Get the weather", func(ctx context.Context, args weatherArgs) (string, error) {
+		if args.Location == "Nowhere" {
+			return "", errors.New("unknown location")
+		}
+		return "sunny", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := agent.NewRegistry(tool)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := agent.New(scriptedModel{}, registry, nil).Run(context.Background(), nil, llm.TextContent(llm.RoleUser, "Weather?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Iterations != 2 || result.Response.Text() != "done" || len(result.Transcript) != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.UsageData.TotalTokens != 4 {
+		t.Errorf("unexpected usage: %+v", result.UsageData)
+	}
+
+	responses := result.Transcript[2].Parts
+	if r := responses[0].(*llm.FunctionResponse); r.ID != "1" || r.IsError || r.Content != "sunny" {
+		t.Errorf("unexpected response: %+v", r)
+	}
+	if r := responses[1].(*llm.FunctionResponse); r.ID != "2" || !r.IsError {
+		t.Errorf("expected an error response: %+v", r)
+	}
+}
+
+func TestAgentMaxIterations(t *testing.T) {
+	tool, _ := agent.NewTool("get_weather", "Get the weather", func(ctx context.Context, args weatherArgs) (string, error) {
+		return "sunny", nil
+	})
+	registry, _ := agent.NewRegistry(tool)
+
+	_, err := agent.New(scriptedModel{}, registry, &agent.Config{MaxIterations: 1}).Run(context.Background(), nil, llm.TextContent(llm.RoleUser, "Weather?"))
+	if !errors.Is(err, agent.ErrMaxIterations) {
+		t.Errorf("expected ErrMaxIterations, got %v", err)
+	}
+}
+
+// choiceModel only calls get_weather when the tool choice requires a tool call, as providers do.
+type choiceModel struct {
+	chats []*llm.ChatContext
+}
+
+func (m *choiceModel) GenerateStream(ctx context.Context, chat *llm.ChatContext, input *llm.Content) *llm.StreamContent {
+	// The agent reuses the chat across turns, so it is copied as sent.
+	c := *chat
+	m.chats = append(m.chats, &c)
+
+	stream := make(chan llm.Segment)
+	close(stream)
+
+	content := llm.TextContent(llm.RoleModel, "done")
+	if tc := chat.ToolChoice; tc != nil && (tc.Mode == llm.ToolChoiceRequired || tc.Name != "") {
+		content.Parts = []llm.Segment{&llm.FunctionCall{ID: "1", Name: "get_weather", Args: map[string]interface{}{"location": "Seoul"}}}
+	}
+
+	return &llm.StreamContent{Content: content, Stream: stream, FinishReason: llm.FinishReasonStop}
+}
+
+func (m *choiceModel) Close() error { return nil }
+func (m *choiceModel) Name() string { return "choice" }
+
+func TestAgentRunChatOptions(t *testing.T) {
+	tool, _ := agent.NewTool("get_weather", "Get the weather", func(ctx context.Context, args weatherArgs) (string, error) {
+		return "sunny", nil
+	})
+	registry, _ := agent.NewRegistry(tool)
+
+	model := &choiceModel{}
+	chat := &llm.ChatContext{
+		ToolChoice:         &llm.ToolChoice{Mode: llm.ToolChoiceRequired},
+		SystemInstruction:  "Answer briefly.",
+		SystemCacheControl: &llm.CacheControl{},
+	}
+	result, err := agent.New(model, registry, nil).Run(context.Background(), chat, llm.TextContent(llm.RoleUser, "Weather?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Iterations != 2 || result.Response.Text() != "done" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if model.chats[0].ToolChoice != chat.ToolChoice {
+		t.Errorf("the first request did not keep the tool choice: %+v", model.chats[0].ToolChoice)
+	}
+	if model.chats[1].ToolChoice != nil {
+		t.Errorf("the tool choice was forced after the first request: %+v", model.chats[1].ToolChoice)
+	}
+	for i, c := range model.chats {
+		if c.SystemCacheControl != chat.SystemCacheControl || c.SystemInstruction != chat.SystemInstruction {
+			t.Errorf("request %d did not keep the chat options: %+v", i, c)
+		}
+	}
+	if len(chat.Contents) != 0 || len(chat.Tools) != 0 || chat.ToolChoice == nil {
+		t.Errorf("the chat of the caller was modified: %+v", chat)
+	}
+}
+
+func TestRegistryCallInvalidArgs(t *testing.T) {
+	type addArgs struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}
+
+	tool, err := agent.NewTool("add", "Add two numbers", func(ctx context.Context, args addArgs) (int, error) {
+		return args.A + args.B, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, _ := agent.NewRegistry(tool)
+
+	resp := registry.Call(context.Background(), &llm.FunctionCall{Name: "add", Args: map[string]interface{}{"a": "1", "b": 2}})
+	if resp.IsError || resp.Content != 3 {
+		t.Errorf("expected the arguments to be coerced: %+v", resp)
+	}
+
+	resp = registry.Call(context.Background(), &llm.FunctionCall{Name: "add", Args: map[string]interface{}{"a": "one"}})
+	content, _ := resp.Content.(map[string]interface{})
+	if errs, _ := content["errors"].([]*llm.ValidationError); !resp.IsError || len(errs) != 2 {
+		t.Errorf("expected an error for each invalid argument: %+v", resp)
+	}
+}
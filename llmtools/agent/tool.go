@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+var (
+	ErrDuplicateTool = errors.New("agent: duplicate tool name")
+	ErrUnknownTool   = errors.New("agent: unknown tool")
+	ErrInvalidTool   = errors.New("agent: invalid tool")
+)
+
+// Tool is a function that can be called by the model.
+type Tool struct {
+	Declaration *llm.FunctionDeclaration
+
+	call func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+}
+
+// NewTool returns a tool that decodes the arguments of a call into A and calls fn.
+// The schema of the parameters is derived from A, which must be a struct type.
+func NewTool[A any, R any](name, description string, fn func(ctx context.Context, args A) (R, error)) (*Tool, error) {
+	schema, err := llm.SchemaFor[A]()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTool, name, err)
+	}
+	if schema.Type != llm.OpenAPITypeObject {
+		return nil, fmt.Errorf("%w: %s: arguments must be a struct", ErrInvalidTool, name)
+	}
+
+	return &Tool{
+		Declaration: &llm.FunctionDeclaration{
+			Name:        name,
+			Description: description,
+			Schema:      schema,
+		},
+		call: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			data, err := json.Marshal(args)
+			if err != nil {
+				return nil, err
+			}
+
+			var a A
+			if err := json.Unmarshal(data, &a); err != nil {
+				return nil, err
+			}
+
+			return fn(ctx, a)
+		},
+	}, nil
+}
+
+// Registry is a set of tools, safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]*Tool
+	order []string
+}
+
+func NewRegistry(tools ...*Tool) (*Registry, error) {
+	r := &Registry{}
+	for _, t := range tools {
+		if err := r.Register(t); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds the tool to the registry.
+func (r *Registry) Register(t *Tool) error {
+	if t == nil || t.Declaration == nil || t.call == nil {
+		return ErrInvalidTool
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tools == nil {
+		r.tools = make(map[string]*Tool)
+	}
+	if _, ok := r.tools[t.Declaration.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateTool, t.Declaration.Name)
+	}
+
+	r.tools[t.Declaration.Name] = t
+	r.order = append(r.order, t.Declaration.Name)
+	return nil
+}
+
+// Declarations returns the declarations of the tools in registration order.
+func (r *Registry) Declarations() []*llm.FunctionDeclaration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decls := make([]*llm.FunctionDeclaration, len(r.order))
+	for i, name := range r.order {
+		decls[i] = r.tools[name].Declaration
+	}
+	return decls
+}
+
+// Call validates the arguments of the function call against the declaration of the tool,
+// executes it and returns its response. Errors, including invalid arguments, unknown tools
+// and panics, are reported as a response with IsError set.
+func (r *Registry) Call(ctx context.Context, fc *llm.FunctionCall) (resp *llm.FunctionResponse) {
+	resp = &llm.FunctionResponse{
+		Name: fc.Name,
+		ID:   fc.ID,
+	}
+
+	r.mu.RLock()
+	t, ok := r.tools[fc.Name]
+	r.mu.RUnlock()
+	if !ok {
+		resp.Content = errorContent(fmt.Errorf("%w: %s", ErrUnknownTool, fc.Name))
+		resp.IsError = true
+		return resp
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			resp.Content = errorContent(fmt.Errorf("agent: tool %s panicked: %v", fc.Name, p))
+			resp.IsError = true
+		}
+	}()
+
+	args, err := t.Declaration.ValidateArgs(fc.Args)
+	if err != nil {
+		var aerr *llm.ArgumentsError
+		if errors.As(err, &aerr) {
+			return aerr.Response(fc)
+		}
+		resp.Content = errorContent(err)
+		resp.IsError = true
+		return resp
+	}
+
+	result, err := t.call(ctx, args)
+	if err != nil {
+		resp.Content = errorContent(err)
+		resp.IsError = true
+		return resp
+	}
+
+	resp.Content = result
+	return resp
+}
+
+func errorContent(err error) interface{} {
+	return map[string]interface{}{"error": err.Error()}
+}
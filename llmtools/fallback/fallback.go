@@ -0,0 +1,108 @@
+// Package fallback chains models so that a request falls through to the next model
+// when a model fails with a transient error.
+package fallback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+type Config struct {
+	ShouldFallback func(error) bool                                      // Reports whether a failed request should be sent to the next model (default: IsFallbackError)
+	OnServe        func(ctx context.Context, index int, model llm.Model) // Called with the model whose response is returned, after its stream is done (optional)
+}
+
+// IsFallbackError reports whether err is an overloaded, rate limit or timeout error.
+func IsFallbackError(err error) bool {
+	if errors.Is(err, llm.ErrOverloaded) || errors.Is(err, llm.ErrRateLimit) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+type Model struct {
+	models []llm.Model
+	config Config
+}
+
+var _ llm.Model = (*Model)(nil)
+
+// New returns a model that sends each request to models in order until one succeeds.
+// A request falls through to the next model only if it failed before the first segment
+// was streamed. Config.OnServe records which model served a request.
+func New(models []llm.Model, config *Config) *Model {
+	if config == nil {
+		config = &Config{}
+	}
+
+	m := &Model{
+		models: models,
+		config: *config,
+	}
+	if m.config.ShouldFallback == nil {
+		m.config.ShouldFallback = IsFallbackError
+	}
+
+	return m
+}
+
+func (m *Model) GenerateStream(ctx context.Context, chat *llm.ChatContext, input *llm.Content) *llm.StreamContent {
+	stream := make(chan llm.Segment, 128)
+	v := &llm.StreamContent{
+		Stream: stream,
+	}
+
+	go func() {
+		defer close(stream)
+
+		var errs []error
+		for i, model := range m.models {
+			upstream := model.GenerateStream(ctx, chat, input)
+			streamed := upstream.Forward(ctx, stream, v, nil) > 0
+
+			if v.Err == nil || streamed || !m.config.ShouldFallback(v.Err) || ctx.Err() != nil {
+				if len(errs) > 0 && v.Err != nil {
+					v.Err = errors.Join(append(errs, fmt.Errorf("%s: %w", model.Name(), v.Err))...)
+				}
+				if m.config.OnServe != nil {
+					m.config.OnServe(ctx, i, model)
+				}
+				return
+			}
+
+			errs = append(errs, fmt.Errorf("%s: %w", model.Name(), v.Err))
+		}
+
+		v.Err = llm.ErrNoResponse
+		if len(errs) > 0 {
+			v.Err = errors.Join(errs...)
+		}
+	}()
+
+	return v
+}
+
+// Close closes all models of the chain.
+func (m *Model) Close() error {
+	var errs []error
+	for _, model := range m.models {
+		errs = append(errs, model.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Name returns the names of the models of the chain separated by commas.
+func (m *Model) Name() string {
+	names := make([]string, len(m.models))
+	for i, model := range m.models {
+		names[i] = model.Name()
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,88 @@
+package fallback_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lemon-mint/coord/internal/llmtest"
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/llmtools/fallback"
+)
+
+func TestFallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		models     []*llmtest.Model
+		wantServed int // Index of the model that served the request, or -1 if none did
+		wantErr    error
+		wantCalls  []int
+	}{
+		{
+			name:       "first succeeds",
+			models:     []*llmtest.Model{{ModelName: "a"}, {ModelName: "b"}},
+			wantServed: 0,
+			wantCalls:  []int{1, 0},
+		},
+		{
+			name:       "falls through",
+			models:     []*llmtest.Model{{ModelName: "a", Err: llm.ErrOverloaded}, {ModelName: "b", Err: context.DeadlineExceeded}, {ModelName: "c"}},
+			wantServed: 2,
+			wantCalls:  []int{1, 1, 1},
+		},
+		{
+			name:       "not a fallback error",
+			models:     []*llmtest.Model{{ModelName: "a", Err: llm.ErrInvalidRequest}, {ModelName: "b"}},
+			wantServed: 0,
+			wantErr:    llm.ErrInvalidRequest,
+			wantCalls:  []int{1, 0},
+		},
+		{
+			name:       "already streamed",
+			models:     []*llmtest.Model{{ModelName: "a", Err: llm.ErrOverloaded, Partial: []llm.Segment{llm.Text("partial")}}, {ModelName: "b"}},
+			wantServed: 0,
+			wantErr:    llm.ErrOverloaded,
+			wantCalls:  []int{1, 0},
+		},
+		{
+			name:       "all fail",
+			models:     []*llmtest.Model{{ModelName: "a", Err: llm.ErrOverloaded}, {ModelName: "b", Err: llm.ErrRateLimit}},
+			wantServed: -1,
+			wantErr:    llm.ErrRateLimit,
+			wantCalls:  []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models := make([]llm.Model, len(tt.models))
+			for i := range tt.models {
+				models[i] = tt.models[i]
+			}
+
+			served := -1
+			config := &fallback.Config{
+				OnServe: func(ctx context.Context, index int, model llm.Model) {
+					served = index
+				},
+			}
+
+			v := fallback.New(models, config).GenerateStream(context.Background(), nil, llm.TextContent(llm.RoleUser, "hi"))
+			err := v.Wait()
+			if tt.wantErr == nil && err != nil || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if served != tt.wantServed {
+				t.Errorf("served by %d, want %d", served, tt.wantServed)
+			}
+			if served >= 0 && v.Model != tt.models[served].Name() {
+				t.Errorf("Model = %q, want the version reported by %s", v.Model, tt.models[served].Name())
+			}
+			for i, m := range tt.models {
+				if m.Calls() != tt.wantCalls[i] {
+					t.Errorf("calls of %s = %d, want %d", m.Name(), m.Calls(), tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package llmtools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+// trimCodeFence removes a markdown code fence around the text.
+func trimCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:] // language tag
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+// DecodeJSON validates the JSON text against the schema and unmarshals it into a value of type T.
+// A nil schema skips the validation.
+func DecodeJSON[T any](text string, schema *llm.Schema) (T, error) {
+	var out T
+
+	data := []byte(trimCodeFence(text))
+
+	if schema != nil {
+		var raw interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return out, fmt.Errorf("%w: %w", llm.ErrInvalidResponse, err)
+		}
+		if err := schema.Validate(raw); err != nil {
+			return out, fmt.Errorf("%w: %w", llm.ErrInvalidResponse, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, fmt.Errorf("%w: %w", llm.ErrInvalidResponse, err)
+	}
+
+	return out, nil
+}
+
+// GenerateJSON generates a response and decodes it with DecodeJSON.
+// The model should be created with a llm.Config.ResponseFormat using the same schema.
+func GenerateJSON[T any](ctx context.Context, m llm.Model, chat *llm.ChatContext, input *llm.Content, schema *llm.Schema) (T, error) {
+	r, err := llm.Generate(ctx, m, chat, input)
+	if err != nil {
+		var out T
+		return out, err
+	}
+
+	return DecodeJSON[T](r.Text(), schema)
+}
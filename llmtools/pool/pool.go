@@ -0,0 +1,213 @@
+// Package pool spreads LLM traffic across several clients of the same provider,
+// such as clients with different API keys or regions.
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/provider"
+)
+
+var ErrNoMembers = errors.New("pool: no members")
+
+const defaultEjectDuration = 30 * time.Second
+
+type Strategy int
+
+const (
+	StrategyWeighted      Strategy = iota // Smooth weighted round-robin over the members
+	StrategyLeastInflight                 // Member with the fewest requests in flight
+)
+
+type Member struct {
+	Client provider.LLMClient
+	Weight int // Relative share of the requests for StrategyWeighted (default: 1)
+}
+
+type Config struct {
+	Strategy      Strategy
+	EjectDuration time.Duration    // Time a member is ejected for after a failure (default: 30s, or the Retry-After delay if longer)
+	ShouldEject   func(error) bool // Reports whether a failure ejects the member (default: IsEjectError)
+}
+
+// IsEjectError reports whether err is a rate limit or authentication error.
+func IsEjectError(err error) bool {
+	return errors.Is(err, llm.ErrRateLimit) || errors.Is(err, llm.ErrAuthentication)
+}
+
+type member struct {
+	client       provider.LLMClient
+	weight       int
+	current      int // Current weight of the smooth weighted round-robin
+	inflight     int
+	ejectedUntil time.Time
+}
+
+// Client is a provider.LLMClient that sends each request to one of its members.
+// A member that fails with an eject error receives no requests until its ejection ends,
+// unless all members are ejected. Failed requests are not retried on another member.
+type Client struct {
+	mu      sync.Mutex
+	members []*member
+	config  Config
+}
+
+var _ provider.LLMClient = (*Client)(nil)
+
+func New(members []Member, config *Config) (*Client, error) {
+	if len(members) == 0 {
+		return nil, ErrNoMembers
+	}
+	if config == nil {
+		config = &Config{}
+	}
+
+	c := &Client{
+		members: make([]*member, len(members)),
+		config:  *config,
+	}
+	if c.config.EjectDuration <= 0 {
+		c.config.EjectDuration = defaultEjectDuration
+	}
+	if c.config.ShouldEject == nil {
+		c.config.ShouldEject = IsEjectError
+	}
+
+	for i := range members {
+		c.members[i] = &member{client: members[i].Client, weight: members[i].Weight}
+		if c.members[i].weight <= 0 {
+			c.members[i].weight = 1
+		}
+	}
+
+	return c, nil
+}
+
+// acquire selects the member for the next request and counts it as in flight.
+func (c *Client) acquire() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	available := make([]int, 0, len(c.members))
+	for i, m := range c.members {
+		if !now.Before(m.ejectedUntil) {
+			available = append(available, i)
+		}
+	}
+	if len(available) == 0 {
+		for i := range c.members {
+			available = append(available, i)
+		}
+	}
+
+	selected := available[0]
+	switch c.config.Strategy {
+	case StrategyLeastInflight:
+		for _, i := range available {
+			if c.members[i].inflight < c.members[selected].inflight {
+				selected = i
+			}
+		}
+	default:
+		var total int
+		for _, i := range available {
+			c.members[i].current += c.members[i].weight
+			total += c.members[i].weight
+			if c.members[i].current > c.members[selected].current {
+				selected = i
+			}
+		}
+		c.members[selected].current -= total
+	}
+
+	c.members[selected].inflight++
+	return selected
+}
+
+// release ends the request to the member and ejects the member if the request failed with an eject error.
+func (c *Client) release(i int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := c.members[i]
+	m.inflight--
+
+	if err != nil && c.config.ShouldEject(err) {
+		d := c.config.EjectDuration
+		var retryAfter *llm.RetryAfterError
+		if errors.As(err, &retryAfter) && retryAfter.Delay > d {
+			d = retryAfter.Delay
+		}
+		m.ejectedUntil = time.Now().Add(d)
+	}
+}
+
+// NewLLM creates the model on every member and returns a model that spreads its requests over them.
+func (c *Client) NewLLM(model string, config *llm.Config) (llm.Model, error) {
+	models := make([]llm.Model, 0, len(c.members))
+	for _, m := range c.members {
+		v, err := m.client.NewLLM(model, config)
+		if err != nil {
+			for _, v := range models {
+				v.Close()
+			}
+			return nil, err
+		}
+		models = append(models, v)
+	}
+
+	return &pooledModel{pool: c, models: models}, nil
+}
+
+// Close closes the clients of all members.
+func (c *Client) Close() error {
+	var errs []error
+	for _, m := range c.members {
+		errs = append(errs, m.client.Close())
+	}
+	return errors.Join(errs...)
+}
+
+type pooledModel struct {
+	pool   *Client
+	models []llm.Model
+}
+
+var _ llm.Model = (*pooledModel)(nil)
+
+func (m *pooledModel) GenerateStream(ctx context.Context, chat *llm.ChatContext, input *llm.Content) *llm.StreamContent {
+	i := m.pool.acquire()
+	upstream := m.models[i].GenerateStream(ctx, chat, input)
+
+	stream := make(chan llm.Segment, 128)
+	v := &llm.StreamContent{
+		Stream: stream,
+	}
+
+	go func() {
+		defer close(stream)
+		// The member is judged by its own error, not by a cancellation of the caller.
+		defer func() { m.pool.release(i, upstream.Err) }()
+
+		upstream.Forward(ctx, stream, v, nil)
+	}()
+
+	return v
+}
+
+func (m *pooledModel) Close() error {
+	var errs []error
+	for _, model := range m.models {
+		errs = append(errs, model.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (m *pooledModel) Name() string {
+	return m.models[0].Name()
+}
@@ -0,0 +1,94 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lemon-mint/coord/internal/llmtest"
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/llmtools/pool"
+)
+
+// stubClient serves every model with its fake model, so the fake counts the requests of the client.
+type stubClient struct {
+	model *llmtest.Model
+}
+
+func (c *stubClient) NewLLM(model string, config *llm.Config) (llm.Model, error) {
+	return c.model, nil
+}
+
+func (c *stubClient) Close() error { return nil }
+
+func newModel(t *testing.T, members []pool.Member, config *pool.Config) llm.Model {
+	t.Helper()
+
+	client, err := pool.New(members, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := client.NewLLM("test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func generate(model llm.Model) error {
+	return model.GenerateStream(context.Background(), nil, llm.TextContent(llm.RoleUser, "hi")).Wait()
+}
+
+func TestWeighted(t *testing.T) {
+	a, b := &stubClient{model: &llmtest.Model{}}, &stubClient{model: &llmtest.Model{}}
+	model := newModel(t, []pool.Member{{Client: a, Weight: 3}, {Client: b}}, nil)
+
+	for range 8 {
+		if err := generate(model); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if a.model.Calls() != 6 || b.model.Calls() != 2 {
+		t.Errorf("calls = %d, %d, want 6, 2", a.model.Calls(), b.model.Calls())
+	}
+}
+
+func TestLeastInflight(t *testing.T) {
+	block := make(chan struct{})
+	a, b := &stubClient{model: &llmtest.Model{Block: block}}, &stubClient{model: &llmtest.Model{}}
+	model := newModel(t, []pool.Member{{Client: a}, {Client: b}}, &pool.Config{Strategy: pool.StrategyLeastInflight})
+
+	held := model.GenerateStream(context.Background(), nil, llm.TextContent(llm.RoleUser, "hi"))
+	for range 3 {
+		if err := generate(model); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(block)
+	held.Wait()
+
+	if a.model.Calls() != 1 || b.model.Calls() != 3 {
+		t.Errorf("calls = %d, %d, want 1, 3", a.model.Calls(), b.model.Calls())
+	}
+}
+
+func TestEject(t *testing.T) {
+	a, b := &stubClient{model: &llmtest.Model{Err: llm.ErrRateLimit}}, &stubClient{model: &llmtest.Model{}}
+	model := newModel(t, []pool.Member{{Client: a}, {Client: b}}, nil)
+
+	for range 4 {
+		generate(model)
+	}
+
+	if a.model.Calls() != 1 || b.model.Calls() != 3 {
+		t.Errorf("calls = %d, %d, want 1, 3", a.model.Calls(), b.model.Calls())
+	}
+}
+
+func TestNoMembers(t *testing.T) {
+	if _, err := pool.New(nil, nil); !errors.Is(err, pool.ErrNoMembers) {
+		t.Errorf("err = %v, want %v", err, pool.ErrNoMembers)
+	}
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"encoding/json"
+
+	"github.com/lemon-mint/coord/llm"
+)
+
+const (
+	charsPerToken      = 4    // Rough number of characters per token of English text
+	dataTokensEstimate = 1000 // Rough number of tokens of an image, audio or document part
+)
+
+// EstimateTokens roughly estimates the number of input tokens of a request from the length
+// of its text. Images and other data count a fixed number of tokens.
+func EstimateTokens(chat *llm.ChatContext, input *llm.Content) int {
+	var chars, tokens int
+	if chat != nil {
+		chars += len(chat.SystemInstruction)
+		for _, tool := range chat.Tools {
+			if b, err := json.Marshal(tool); err == nil {
+				chars += len(b)
+			}
+		}
+		for _, c := range chat.Contents {
+			c, t := estimateContent(c)
+			chars += c
+			tokens += t
+		}
+	}
+
+	c, t := estimateContent(input)
+	chars += c
+	tokens += t
+
+	return tokens + (chars+charsPerToken-1)/charsPerToken
+}
+
+func estimateContent(c *llm.Content) (chars, tokens int) {
+	if c == nil {
+		return 0, 0
+	}
+
+	for _, part := range c.Parts {
+		switch v := part.(type) {
+		case llm.Text:
+			chars += len(v)
+		case *llm.ThinkingBlock:
+			chars += len(v.Data)
+		case *llm.FunctionCall, *llm.FunctionResponse:
+			if b, err := json.Marshal(v); err == nil {
+				chars += len(b)
+			}
+		case *llm.InlineData, *llm.FileData:
+			tokens += dataTokensEstimate
+		}
+	}
+	return chars, tokens
+}
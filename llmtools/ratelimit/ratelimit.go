@@ -0,0 +1,246 @@
+// Package ratelimit keeps requests to models under request, token and concurrency limits
+// before the provider rejects them.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/provider"
+)
+
+type Limits struct {
+	RequestsPerMinute     int // Maximum number of requests per minute (0 for no limit)
+	InputTokensPerMinute  int // Maximum number of input tokens per minute (0 for no limit)
+	OutputTokensPerMinute int // Maximum number of output tokens per minute (0 for no limit)
+	MaxConcurrent         int // Maximum number of requests in flight (0 for no limit)
+
+	EstimateTokens func(chat *llm.ChatContext, input *llm.Content) int // Estimates the input tokens of a request (default: EstimateTokens)
+}
+
+// bucket is a token bucket that refills to its capacity in a minute.
+// The tokens may go negative when a request uses more than was taken for it.
+type bucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64 // Tokens per second
+	last     time.Time
+}
+
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	return &bucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// wait returns how long it takes until n tokens are available. Requests larger than the
+// capacity only wait for a full bucket.
+func (b *bucket) wait(n float64) time.Duration {
+	n = min(n, b.capacity)
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// Budget is a set of limits shared by all models that use it.
+type Budget struct {
+	mu       sync.Mutex
+	requests *bucket
+	input    *bucket
+	output   *bucket
+	released chan struct{} // Closed when a request is released
+
+	maxConcurrent int
+	inflight      int
+	estimate      func(chat *llm.ChatContext, input *llm.Content) int
+}
+
+func NewBudget(limits *Limits) *Budget {
+	if limits == nil {
+		limits = &Limits{}
+	}
+
+	now := time.Now()
+	b := &Budget{
+		requests:      newBucket(limits.RequestsPerMinute, now),
+		input:         newBucket(limits.InputTokensPerMinute, now),
+		output:        newBucket(limits.OutputTokensPerMinute, now),
+		released:      make(chan struct{}),
+		maxConcurrent: limits.MaxConcurrent,
+		estimate:      limits.EstimateTokens,
+	}
+	if b.estimate == nil {
+		b.estimate = EstimateTokens
+	}
+
+	return b
+}
+
+// acquire waits until a request with the estimated input tokens fits in the budget and takes
+// its request and input tokens. Output tokens are only taken after the request, so a request
+// starts as soon as the output tokens are not used up.
+func (b *Budget) acquire(ctx context.Context, inputTokens int) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+
+		var delay time.Duration
+		if b.requests != nil {
+			b.requests.refill(now)
+			delay = max(delay, b.requests.wait(1))
+		}
+		if b.input != nil {
+			b.input.refill(now)
+			delay = max(delay, b.input.wait(float64(inputTokens)))
+		}
+		if b.output != nil {
+			b.output.refill(now)
+			delay = max(delay, b.output.wait(1))
+		}
+		full := b.maxConcurrent > 0 && b.inflight >= b.maxConcurrent
+
+		if delay == 0 && !full {
+			if b.requests != nil {
+				b.requests.tokens--
+			}
+			if b.input != nil {
+				b.input.tokens -= float64(inputTokens)
+			}
+			b.inflight++
+			b.mu.Unlock()
+			return nil
+		}
+
+		released := b.released
+		b.mu.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if delay > 0 {
+			timer = time.NewTimer(delay)
+			timeout = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-timeout:
+		case <-released:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// release ends a request and reconciles the estimated input tokens and the output tokens
+// with the usage reported by the provider.
+func (b *Budget) release(inputTokens int, usage *llm.UsageData) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.inflight--
+	close(b.released)
+	b.released = make(chan struct{})
+
+	if usage == nil {
+		return
+	}
+
+	now := time.Now()
+	if b.input != nil {
+		b.input.refill(now)
+		b.input.tokens = min(b.input.capacity, b.input.tokens-float64(usage.InputTokens-inputTokens))
+	}
+	if b.output != nil {
+		b.output.refill(now)
+		b.output.tokens -= float64(usage.OutputTokens)
+	}
+}
+
+type model struct {
+	upstream llm.Model
+	budget   *Budget
+}
+
+var _ llm.Model = (*model)(nil)
+
+// New returns a model that waits for the budget before each request to upstream.
+// A request that can not start before the context ends fails with the error of the context.
+func New(upstream llm.Model, budget *Budget) llm.Model {
+	return &model{upstream: upstream, budget: budget}
+}
+
+func (m *model) GenerateStream(ctx context.Context, chat *llm.ChatContext, input *llm.Content) *llm.StreamContent {
+	stream := make(chan llm.Segment, 128)
+	v := &llm.StreamContent{
+		Stream: stream,
+	}
+
+	go func() {
+		defer close(stream)
+
+		inputTokens := m.budget.estimate(chat, input)
+		if err := m.budget.acquire(ctx, inputTokens); err != nil {
+			v.Err = err
+			return
+		}
+
+		upstream := m.upstream.GenerateStream(ctx, chat, input)
+		defer func() { m.budget.release(inputTokens, upstream.UsageData) }()
+
+		upstream.Forward(ctx, stream, v, nil)
+	}()
+
+	return v
+}
+
+func (m *model) Close() error {
+	return m.upstream.Close()
+}
+
+func (m *model) Name() string {
+	return m.upstream.Name()
+}
+
+type client struct {
+	upstream provider.LLMClient
+	budget   *Budget
+}
+
+var _ provider.LLMClient = (*client)(nil)
+
+// NewClient returns a client whose models all share the budget.
+func NewClient(upstream provider.LLMClient, budget *Budget) provider.LLMClient {
+	return &client{upstream: upstream, budget: budget}
+}
+
+func (c *client) NewLLM(model string, config *llm.Config) (llm.Model, error) {
+	m, err := c.upstream.NewLLM(model, config)
+	if err != nil {
+		return nil, err
+	}
+	return New(m, c.budget), nil
+}
+
+func (c *client) Close() error {
+	return c.upstream.Close()
+}
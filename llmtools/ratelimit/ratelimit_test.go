@@ -0,0 +1,109 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lemon-mint/coord/internal/llmtest"
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/llmtools/ratelimit"
+)
+
+func generate(ctx context.Context, model llm.Model) error {
+	return model.GenerateStream(ctx, nil, llm.TextContent(llm.RoleUser, "hi")).Wait()
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	upstream := &llmtest.Model{Delay: 10 * time.Millisecond}
+	model := ratelimit.New(upstream, ratelimit.NewBudget(&ratelimit.Limits{MaxConcurrent: 2}))
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := generate(context.Background(), model); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if upstream.Calls() != 6 || upstream.MaxInflight() != 2 {
+		t.Errorf("calls = %d, max in flight = %d, want 6, 2", upstream.Calls(), upstream.MaxInflight())
+	}
+}
+
+func TestSharedRequestBudget(t *testing.T) {
+	budget := ratelimit.NewBudget(&ratelimit.Limits{RequestsPerMinute: 2})
+	a, b := &llmtest.Model{}, &llmtest.Model{}
+
+	if err := generate(context.Background(), ratelimit.New(a, budget)); err != nil {
+		t.Fatal(err)
+	}
+	if err := generate(context.Background(), ratelimit.New(b, budget)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := generate(ctx, ratelimit.New(a, budget)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if a.Calls() != 1 || b.Calls() != 1 {
+		t.Errorf("calls = %d, %d, want 1, 1", a.Calls(), b.Calls())
+	}
+}
+
+func TestOutputTokens(t *testing.T) {
+	upstream := &llmtest.Model{Usage: &llm.UsageData{InputTokens: 1, OutputTokens: 1000}}
+	model := ratelimit.New(upstream, ratelimit.NewBudget(&ratelimit.Limits{OutputTokensPerMinute: 600}))
+
+	if err := generate(context.Background(), model); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := generate(ctx, model); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestAbandonedStream(t *testing.T) {
+	parts := make([]llm.Segment, 1000)
+	for i := range parts {
+		parts[i] = llm.Text("x")
+	}
+	model := ratelimit.New(&llmtest.Model{Parts: parts}, ratelimit.NewBudget(&ratelimit.Limits{MaxConcurrent: 1}))
+
+	// The caller stops reading the stream and cancels the request.
+	ctx, cancel := context.WithCancel(context.Background())
+	held := model.GenerateStream(ctx, nil, llm.TextContent(llm.RoleUser, "hi"))
+	<-held.Stream
+	cancel()
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := generate(ctx, model); err != nil {
+		t.Errorf("the abandoned request was not released: %v", err)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	chat := &llm.ChatContext{
+		SystemInstruction: "12345678",
+		Contents:          []*llm.Content{llm.TextContent(llm.RoleUser, "1234")},
+	}
+	input := &llm.Content{Role: llm.RoleUser, Parts: []llm.Segment{llm.Text("1234"), &llm.InlineData{MIMEType: "image/png"}}}
+
+	if got := ratelimit.EstimateTokens(chat, input); got != 1004 {
+		t.Errorf("EstimateTokens = %d, want 1004", got)
+	}
+}
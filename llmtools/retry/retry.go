@@ -0,0 +1,281 @@
+// Package retry wraps models to retry requests that failed with a transient error.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/rerank"
+	"github.com/lemon-mint/coord/stt"
+	"github.com/lemon-mint/coord/tts"
+)
+
+const (
+	defaultMaxAttempts = 4
+	defaultBaseDelay   = 500 * time.Millisecond
+	defaultMaxDelay    = 30 * time.Second
+)
+
+type Config struct {
+	MaxAttempts int              // Maximum number of attempts including the first one (default: 4)
+	BaseDelay   time.Duration    // Delay before the first retry, doubled for every following retry (default: 500ms)
+	MaxDelay    time.Duration    // Maximum delay between attempts (default: 30s)
+	Retryable   func(error) bool // Reports whether a failed request should be retried (default: IsRetryable)
+}
+
+func (c *Config) withDefaults() Config {
+	if c == nil {
+		c = &Config{}
+	}
+
+	v := *c
+	if v.MaxAttempts <= 0 {
+		v.MaxAttempts = defaultMaxAttempts
+	}
+	if v.BaseDelay <= 0 {
+		v.BaseDelay = defaultBaseDelay
+	}
+	if v.MaxDelay <= 0 {
+		v.MaxDelay = defaultMaxDelay
+	}
+	if v.Retryable == nil {
+		v.Retryable = IsRetryable
+	}
+	return v
+}
+
+var retryableErrors = []error{
+	llm.ErrRateLimit, llm.ErrOverloaded, llm.ErrInternalServer,
+	embedding.ErrRateLimit, embedding.ErrOverloaded, embedding.ErrInternalServer,
+	tts.ErrRateLimit, tts.ErrOverloaded, tts.ErrInternalServer,
+	stt.ErrRateLimit, stt.ErrOverloaded, stt.ErrInternalServer,
+	rerank.ErrRateLimit, rerank.ErrOverloaded, rerank.ErrInternalServer,
+}
+
+// IsRetryable reports whether err is a rate limit, overloaded or internal server error
+// of any model type.
+func IsRetryable(err error) bool {
+	for _, target := range retryableErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// wait sleeps before the next attempt and reports whether the request failed with err
+// should be retried. The delay is the Retry-After delay of the provider if there is one,
+// or a jittered exponential backoff otherwise. No retry is made if the provider asks to
+// wait longer than MaxDelay or the context ends before the delay has elapsed.
+func (c *Config) wait(ctx context.Context, attempt int, err error) bool {
+	if attempt+1 >= c.MaxAttempts || !c.Retryable(err) {
+		return false
+	}
+
+	var delay time.Duration
+	var retryAfter *llm.RetryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.Delay > 0 {
+		if retryAfter.Delay > c.MaxDelay {
+			return false
+		}
+		delay = retryAfter.Delay
+	} else {
+		delay = c.backoff(attempt)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// backoff returns a random delay between zero and BaseDelay*2^attempt, capped at MaxDelay.
+func (c *Config) backoff(attempt int) time.Duration {
+	d := c.BaseDelay
+	for i := 0; i < attempt && d < c.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > c.MaxDelay {
+		d = c.MaxDelay
+	}
+	return rand.N(d) + 1
+}
+
+func do[T any](ctx context.Context, c *Config, fn func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		v, err := fn()
+		if err == nil || !c.wait(ctx, attempt, err) {
+			return v, err
+		}
+	}
+}
+
+type llmModel struct {
+	upstream llm.Model
+	config   Config
+}
+
+var _ llm.Model = (*llmModel)(nil)
+
+// NewLLM returns a model that retries failed requests to upstream. A request is only retried
+// if it failed before the first segment was streamed, since the segments already delivered
+// can not be taken back.
+func NewLLM(upstream llm.Model, config *Config) llm.Model {
+	return &llmModel{upstream: upstream, config: config.withDefaults()}
+}
+
+func (m *llmModel) GenerateStream(ctx context.Context, chat *llm.ChatContext, input *llm.Content) *llm.StreamContent {
+	stream := make(chan llm.Segment, 128)
+	v := &llm.StreamContent{
+		Stream: stream,
+	}
+
+	go func() {
+		defer close(stream)
+
+		for attempt := 0; ; attempt++ {
+			upstream := m.upstream.GenerateStream(ctx, chat, input)
+			streamed := upstream.Forward(ctx, stream, v, nil) > 0
+
+			if v.Err == nil || streamed || !m.config.wait(ctx, attempt, v.Err) {
+				return
+			}
+		}
+	}()
+
+	return v
+}
+
+func (m *llmModel) Close() error {
+	return m.upstream.Close()
+}
+
+func (m *llmModel) Name() string {
+	return m.upstream.Name()
+}
+
+type embeddingModel struct {
+	upstream embedding.Model
+	config   Config
+}
+
+var (
+	_ embedding.BatchModel   = (*embeddingModel)(nil)
+	_ embedding.ResultModel  = (*embeddingModel)(nil)
+	_ embedding.ContentModel = (*embeddingModel)(nil)
+)
+
+// NewEmbedding returns an embedding model that retries failed requests to upstream.
+func NewEmbedding(upstream embedding.Model, config *Config) embedding.Model {
+	return &embeddingModel{upstream: upstream, config: config.withDefaults()}
+}
+
+func (m *embeddingModel) TextEmbedding(ctx context.Context, text string, task embedding.TaskType) ([]float64, error) {
+	return do(ctx, &m.config, func() ([]float64, error) {
+		return m.upstream.TextEmbedding(ctx, text, task)
+	})
+}
+
+func (m *embeddingModel) BatchTextEmbedding(ctx context.Context, texts []string, task embedding.TaskType) ([][]float64, error) {
+	return do(ctx, &m.config, func() ([][]float64, error) {
+		return embedding.BatchTextEmbedding(ctx, m.upstream, texts, task)
+	})
+}
+
+func (m *embeddingModel) EmbedTexts(ctx context.Context, texts []string, task embedding.TaskType) (*embedding.Result, error) {
+	return do(ctx, &m.config, func() (*embedding.Result, error) {
+		return embedding.EmbedTexts(ctx, m.upstream, texts, task)
+	})
+}
+
+func (m *embeddingModel) ContentEmbedding(ctx context.Context, parts []llm.Segment, task embedding.TaskType) ([]float64, error) {
+	return do(ctx, &m.config, func() ([]float64, error) {
+		return embedding.ContentEmbedding(ctx, m.upstream, parts, task)
+	})
+}
+
+type ttsModel struct {
+	upstream tts.Model
+	config   Config
+}
+
+var (
+	_ tts.ContextModel = (*ttsModel)(nil)
+	_ tts.StreamModel  = (*ttsModel)(nil)
+)
+
+// NewTTS returns a text-to-speech model that retries failed requests to upstream.
+// Streamed requests are only retried if they failed before the first audio chunk was delivered.
+func NewTTS(upstream tts.Model, config *Config) tts.Model {
+	return &ttsModel{upstream: upstream, config: config.withDefaults()}
+}
+
+func (m *ttsModel) GenerateSpeech(ctx context.Context, text string) (*tts.AudioFile, error) {
+	return do(ctx, &m.config, func() (*tts.AudioFile, error) {
+		return m.upstream.GenerateSpeech(ctx, text)
+	})
+}
+
+func (m *ttsModel) GenerateSpeechWithContext(ctx context.Context, text string, sc *tts.SpeechContext) (*tts.AudioFile, error) {
+	return do(ctx, &m.config, func() (*tts.AudioFile, error) {
+		return tts.GenerateSpeechWithContext(ctx, m.upstream, text, sc)
+	})
+}
+
+func (m *ttsModel) GenerateSpeechStream(ctx context.Context, text string) *tts.AudioStream {
+	stream := make(chan []byte, 8)
+	v := &tts.AudioStream{
+		Stream: stream,
+	}
+
+	go func() {
+		defer close(stream)
+
+		for attempt := 0; ; attempt++ {
+			upstream := tts.GenerateSpeechStream(ctx, m.upstream, text)
+
+			var streamed, canceled bool
+			for chunk := range upstream.Stream {
+				if !streamed {
+					v.Format = upstream.Format
+					streamed = true
+				}
+				if canceled {
+					continue
+				}
+
+				select {
+				case stream <- chunk:
+				case <-ctx.Done():
+					canceled = true
+				}
+			}
+
+			err := upstream.Err
+			if canceled && err == nil {
+				err = ctx.Err()
+			}
+
+			if err == nil || streamed || !m.config.wait(ctx, attempt, err) {
+				v.Format = upstream.Format
+				v.Err = err
+				return
+			}
+		}
+	}()
+
+	return v
+}
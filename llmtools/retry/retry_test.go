@@ -0,0 +1,89 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/internal/llmtest"
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/llmtools/retry"
+)
+
+// flakyEmbedding fails the first failures calls with err.
+type flakyEmbedding struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (m *flakyEmbedding) TextEmbedding(ctx context.Context, text string, task embedding.TaskType) ([]float64, error) {
+	m.calls++
+	if m.calls <= m.failures {
+		return nil, m.err
+	}
+	return []float64{1}, nil
+}
+
+var testConfig = &retry.Config{BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+func TestLLMRetry(t *testing.T) {
+	rateLimit := fmt.Errorf("%w: slow down", llm.ErrRateLimit)
+
+	tests := []struct {
+		name      string
+		upstream  *llmtest.Model
+		wantErr   error
+		wantCalls int
+	}{
+		{"succeeds after retries", &llmtest.Model{Failures: 3, Err: rateLimit}, nil, 4},
+		{"gives up after max attempts", &llmtest.Model{Failures: 4, Err: rateLimit}, llm.ErrRateLimit, 4},
+		{"not retryable", &llmtest.Model{Failures: 1, Err: llm.ErrInvalidRequest}, llm.ErrInvalidRequest, 1},
+		{"already streamed", &llmtest.Model{Failures: 1, Err: llm.ErrOverloaded, Partial: []llm.Segment{llm.Text("partial")}}, llm.ErrOverloaded, 1},
+		{"retry after", &llmtest.Model{Failures: 1, Err: &llm.RetryAfterError{Err: rateLimit, Delay: time.Millisecond}}, nil, 2},
+		{"retry after too long", &llmtest.Model{Failures: 1, Err: &llm.RetryAfterError{Err: rateLimit, Delay: time.Minute}}, llm.ErrRateLimit, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := retry.NewLLM(tt.upstream, testConfig).GenerateStream(context.Background(), nil, llm.TextContent(llm.RoleUser, "hi"))
+			if err := v.Wait(); !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.upstream.Calls() != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", tt.upstream.Calls(), tt.wantCalls)
+			}
+			if tt.wantErr == nil && v.FinishReason != llm.FinishReasonStop {
+				t.Errorf("FinishReason = %q, want %q", v.FinishReason, llm.FinishReasonStop)
+			}
+		})
+	}
+}
+
+func TestEmbeddingRetry(t *testing.T) {
+	upstream := &flakyEmbedding{failures: 2, err: embedding.ErrOverloaded}
+
+	values, err := retry.NewEmbedding(upstream, testConfig).TextEmbedding(context.Background(), "hi", embedding.TaskTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || upstream.calls != 3 {
+		t.Errorf("values = %v, calls = %d", values, upstream.calls)
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	upstream := &flakyEmbedding{failures: 1, err: embedding.ErrRateLimit}
+	config := &retry.Config{BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := retry.NewEmbedding(upstream, config).TextEmbedding(ctx, "hi", embedding.TaskTypeGeneral)
+	if !errors.Is(err, embedding.ErrRateLimit) || upstream.calls != 1 {
+		t.Errorf("err = %v, calls = %d", err, upstream.calls)
+	}
+}
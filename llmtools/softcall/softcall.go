@@ -84,6 +84,22 @@ const yamlTestToolResultResonse = `<reasoning>I should return the exit code of 0
 
 The test_function0 exited with exit code 0.`
 
+// yamlToolChoice returns the instruction that emulates the tool choice, appended to the input.
+func yamlToolChoice(t *llm.ToolChoice) string {
+	if t != nil && t.Name != "" {
+		return fmt.Sprintf("\n\nYou must call the %s tool with a <tool_call> block before providing your final answer.", t.Name)
+	}
+
+	switch t.Effective() {
+	case llm.ToolChoiceNone:
+		return "\n\nDo not call any tools. Provide your final answer without using <tool_call> blocks."
+	case llm.ToolChoiceRequired:
+		return "\n\nYou must call at least one tool with a <tool_call> block before providing your final answer."
+	}
+
+	return ""
+}
+
 func yamlFuncDecl(tools []*llm.FunctionDeclaration) string {
 	if len(tools) == 0 {
 		return ""
@@ -110,6 +126,15 @@ func yamlFuncDecl(tools []*llm.FunctionDeclaration) string {
 	return sb.String()
 }
 
+func findFuncDecl(tools []*llm.FunctionDeclaration, name string) *llm.FunctionDeclaration {
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool
+		}
+	}
+	return nil
+}
+
 func convertToYAMLContent(content *llm.Content) *llm.Content {
 	if content == nil {
 		return nil
@@ -235,17 +260,36 @@ func (g *YAMLSoftCallLLM) GenerateStream(ctx context.Context, chat *llm.ChatCont
 		},
 	})
 
+	// The tools are declared in the prompt, so a cache breakpoint on the tools is placed after the prompt.
+	for _, tool := range chat.Tools {
+		if c := tool.CacheControl; c != nil {
+			if prev := messages[len(messages)-1].CacheControl; prev == nil || c.TTL > prev.TTL {
+				messages[len(messages)-1].CacheControl = c
+			}
+		}
+	}
+
 	for i := range chat.Contents {
 		content := convertToYAMLContent(chat.Contents[i])
 		content.Parts = llmutils.Normalize(content.Parts)
 		messages = append(messages, content)
 	}
 
+	tools := chat.Tools
+	toolChoice := chat.ToolChoice
 	chat = &llm.ChatContext{
 		Contents: messages,
 	}
 	input = convertToYAMLContent(input)
 
+	if note := yamlToolChoice(toolChoice); note != "" && input != nil {
+		input = &llm.Content{
+			Role:         input.Role,
+			Parts:        append(append([]llm.Segment{}, input.Parts...), llm.Text(note)),
+			CacheControl: input.CacheControl,
+		}
+	}
+
 	stream := make(chan llm.Segment, 128)
 	v := &llm.StreamContent{
 		Content: &llm.Content{
@@ -388,6 +432,10 @@ func (g *YAMLSoftCallLLM) GenerateStream(ctx context.Context, chat *llm.ChatCont
 								ID:   callid.OpenAICallID(),
 								Args: call.Parameters,
 							}
+							if decl := findFuncDecl(tools, payload.Name); decl != nil {
+								// YAML is lenient with scalars (e.g. "42" or yes), so the arguments are coerced to the schema.
+								payload.Args = decl.CoerceArgs(payload.Args)
+							}
 							v.Content.Parts = append(v.Content.Parts, payload)
 							select {
 							case stream <- payload:
@@ -424,6 +472,8 @@ func (g *YAMLSoftCallLLM) GenerateStream(ctx context.Context, chat *llm.ChatCont
 		v.Err = resp.Err
 		v.UsageData = resp.UsageData
 		v.FinishReason = resp.FinishReason
+		v.Model = resp.Model
+		v.ResponseID = resp.ResponseID
 	}()
 
 	return v
@@ -0,0 +1,236 @@
+package speech
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultMinLength = 16
+	defaultMaxLength = 200
+)
+
+// Segmenter splits incrementally written text into chunks that end at sentence
+// boundaries. Chunks shorter than MinLength are merged with the following sentence,
+// and text longer than MaxLength without a sentence boundary is split at a clause
+// boundary or a space. Lengths are counted in runes.
+type Segmenter struct {
+	minLength int
+	maxLength int
+
+	abbreviations map[string]bool
+	clauseMarks   string
+
+	buf  []rune
+	scan int // position up to which buf has been checked for boundaries
+}
+
+// NewSegmenter returns a segmenter with the rules of the language (BCP-47 or ISO-639-1).
+// Zero lengths select the defaults.
+func NewSegmenter(language string, minLength, maxLength int) *Segmenter {
+	if minLength <= 0 {
+		minLength = defaultMinLength
+	}
+	if maxLength <= 0 {
+		maxLength = defaultMaxLength
+	}
+	if maxLength < minLength {
+		maxLength = minLength
+	}
+
+	s := &Segmenter{
+		minLength:   minLength,
+		maxLength:   maxLength,
+		clauseMarks: ",;:",
+	}
+
+	lang, _, _ := strings.Cut(strings.ToLower(language), "-")
+	switch lang {
+	case "en", "":
+		s.abbreviations = abbreviationsEN
+	case "de":
+		s.abbreviations = abbreviationsDE
+	case "fr":
+		s.abbreviations = abbreviationsFR
+	case "ja", "zh":
+		s.clauseMarks = "、，；：,;:"
+	case "ar", "fa", "ur":
+		s.clauseMarks = "،؛,;:"
+	}
+
+	return s
+}
+
+var abbreviationsEN = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+	"st": true, "vs": true, "etc": true, "e.g": true, "i.e": true, "inc": true, "ltd": true,
+	"co": true, "no": true, "approx": true, "dept": true, "est": true, "fig": true,
+}
+
+var abbreviationsDE = map[string]bool{
+	"z.b": true, "bzw": true, "usw": true, "nr": true, "dr": true, "prof": true, "ca": true,
+	"vgl": true, "d.h": true, "u.a": true, "hr": true, "fr": true, "str": true,
+}
+
+var abbreviationsFR = map[string]bool{
+	"m": true, "mme": true, "mlle": true, "dr": true, "etc": true, "p.ex": true, "cf": true,
+	"av": true, "bd": true, "n°": true,
+}
+
+// isTerminal reports whether r ends a sentence without a following space.
+func isTerminal(r rune) bool {
+	switch r {
+	case '。', '！', '？', '．', '।', '॥', '؟', '۔', '\n':
+		return true
+	}
+	return false
+}
+
+// isSpacedTerminal reports whether r ends a sentence when followed by a space.
+func isSpacedTerminal(r rune) bool {
+	switch r {
+	case '.', '!', '?', '…':
+		return true
+	}
+	return false
+}
+
+// isCloser reports whether r closes a quotation or a bracket and belongs to the preceding sentence.
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '}', '”', '’', '」', '』', '）', '》', '〉', '】', '*', '_':
+		return true
+	}
+	return false
+}
+
+// isAbbreviation reports whether the period at i ends an abbreviation or an initial.
+func (s *Segmenter) isAbbreviation(i int) bool {
+	if s.buf[i] != '.' {
+		return false
+	}
+
+	start := i
+	for start > 0 && !unicode.IsSpace(s.buf[start-1]) && s.buf[start-1] != '(' {
+		start--
+	}
+	word := strings.ToLower(string(s.buf[start:i]))
+	if word == "" {
+		return false
+	}
+
+	// Single letter initials (e.g. "J. R. R. Tolkien")
+	if len([]rune(word)) == 1 && unicode.IsLetter([]rune(word)[0]) {
+		return true
+	}
+
+	return s.abbreviations[word]
+}
+
+// boundary returns the end of the first sentence starting at s.scan.
+// If it can not be decided without more text, ok is false and s.scan is set to
+// the position where the check has to be resumed.
+func (s *Segmenter) boundary(final bool) (end int, ok bool) {
+	for i := s.scan; i < len(s.buf); i++ {
+		r := s.buf[i]
+
+		if !isTerminal(r) && !isSpacedTerminal(r) {
+			continue
+		}
+
+		j := i + 1
+		for j < len(s.buf) && (isCloser(s.buf[j]) || isSpacedTerminal(s.buf[j])) {
+			j++
+		}
+
+		if isTerminal(r) {
+			return j, true
+		}
+
+		if j == len(s.buf) {
+			if final {
+				return j, true
+			}
+			s.scan = i
+			return 0, false
+		}
+
+		if unicode.IsSpace(s.buf[j]) && !s.isAbbreviation(i) {
+			return j, true
+		}
+
+		i = j - 1
+	}
+
+	s.scan = len(s.buf)
+	return 0, false
+}
+
+// split returns the position where an overlong buffer is split.
+func (s *Segmenter) split() int {
+	limit := s.maxLength
+	for i := limit - 1; i >= s.minLength; i-- {
+		if strings.ContainsRune(s.clauseMarks, s.buf[i]) && (i+1 >= len(s.buf) || unicode.IsSpace(s.buf[i+1]) || s.buf[i] > unicode.MaxLatin1) {
+			return i + 1
+		}
+	}
+	for i := limit - 1; i >= s.minLength; i-- {
+		if unicode.IsSpace(s.buf[i]) {
+			return i
+		}
+	}
+	return limit
+}
+
+func (s *Segmenter) take(end int) string {
+	chunk := strings.TrimSpace(string(s.buf[:end]))
+	s.buf = s.buf[end:]
+	s.scan = 0
+	return chunk
+}
+
+func (s *Segmenter) chunks(final bool) []string {
+	var out []string
+
+	for {
+		end, ok := s.boundary(final)
+		if !ok {
+			break
+		}
+
+		if len([]rune(strings.TrimSpace(string(s.buf[:end])))) < s.minLength {
+			// Merge the short sentence with the next one.
+			s.scan = end
+			continue
+		}
+
+		if chunk := s.take(end); chunk != "" {
+			out = append(out, chunk)
+		}
+	}
+
+	for len(s.buf) > s.maxLength {
+		if chunk := s.take(s.split()); chunk != "" {
+			out = append(out, chunk)
+		}
+	}
+
+	return out
+}
+
+// Write appends the text and returns the chunks completed by it.
+func (s *Segmenter) Write(text string) []string {
+	s.buf = append(s.buf, []rune(text)...)
+	return s.chunks(false)
+}
+
+// Flush returns the remaining text as chunks and resets the segmenter.
+func (s *Segmenter) Flush() []string {
+	out := s.chunks(true)
+	if chunk := strings.TrimSpace(string(s.buf)); chunk != "" {
+		out = append(out, chunk)
+	}
+	s.buf = s.buf[:0]
+	s.scan = 0
+	return out
+}
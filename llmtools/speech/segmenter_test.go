@@ -0,0 +1,70 @@
+package speech_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lemon-mint/coord/llmtools/speech"
+)
+
+func segment(language string, parts ...string) []string {
+	seg := speech.NewSegmenter(language, 8, 80)
+	var out []string
+	for _, p := range parts {
+		out = append(out, seg.Write(p)...)
+	}
+	return append(out, seg.Flush()...)
+}
+
+func TestSegmenter(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		parts    []string
+		want     []string
+	}{
+		{
+			name:  "sentences",
+			parts: []string{"Hi! How are", " you today? I am fine. Th", "anks for asking!"},
+			want:  []string{"Hi! How are you today?", "I am fine.", "Thanks for asking!"},
+		},
+		{
+			name:  "abbreviations and decimals",
+			parts: []string{"Dr. Smith paid 3.50 dollars for it. ", "Then he left."},
+			want:  []string{"Dr. Smith paid 3.50 dollars for it.", "Then he left."},
+		},
+		{
+			name:     "japanese",
+			language: "ja",
+			parts:    []string{"今日はいい天気ですね。", "散歩に行きましょうか？", "はい"},
+			want:     []string{"今日はいい天気ですね。", "散歩に行きましょうか？", "はい"},
+		},
+		{
+			name:  "quotes",
+			parts: []string{`He said "stop right now." And then he left.`},
+			want:  []string{`He said "stop right now."`, "And then he left."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := segment(tt.language, tt.parts...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmenterMaxLength(t *testing.T) {
+	seg := speech.NewSegmenter("en", 4, 20)
+	got := append(seg.Write("one two three, four five six seven eight nine"), seg.Flush()...)
+	for _, c := range got {
+		if len([]rune(c)) > 20 {
+			t.Errorf("chunk %q exceeds the maximum length", c)
+		}
+	}
+	if got[0] != "one two three," {
+		t.Errorf("expected a split at the clause boundary, got %q", got)
+	}
+}
@@ -0,0 +1,169 @@
+// Package speech synthesizes the text of an LLM response while it is being generated.
+package speech
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/tts"
+)
+
+type Config struct {
+	Language string // Language of the text (BCP-47 or ISO-639-1), used for the sentence segmentation rules
+
+	MinLength int // Minimum length of a synthesized chunk in runes (default: 16)
+	MaxLength int // Maximum length of a synthesized chunk in runes (default: 200)
+}
+
+type Chunk struct {
+	Text  string         `json:"text"`
+	Audio *tts.AudioFile `json:"audio"`
+}
+
+type Stream struct {
+	Err error `json:"error"` // Only Available after Stream channel is closed
+
+	Stream <-chan *Chunk `json:"-"` // Audio chunks in the order of the text
+}
+
+func (g *Stream) Wait() error {
+	if g == nil {
+		return tts.ErrNoResponse
+	}
+
+	for range g.Stream {
+	}
+
+	return g.Err
+}
+
+// maxContextChunks is the number of preceding chunks passed as the speech context.
+const maxContextChunks = 3
+
+// Speak splits the text segments of the LLM stream into sentences and synthesizes them
+// one by one while the response is still being generated. If the model implements
+// tts.ContextModel, each sentence is synthesized with the preceding text and requests,
+// and with the following sentence if it is already available.
+func Speak(ctx context.Context, m tts.Model, input *llm.StreamContent, config *Config) *Stream {
+	if config == nil {
+		config = &Config{}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	sentences := make(chan string, 64)
+	stream := make(chan *Chunk, 8)
+	v := &Stream{
+		Stream: stream,
+	}
+
+	var inputErr error
+	go func() {
+		defer close(sentences)
+
+		send := func(chunks []string) {
+			for _, c := range chunks {
+				select {
+				case sentences <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		seg := NewSegmenter(config.Language, config.MinLength, config.MaxLength)
+		// The input stream is drained even after a failure, so that the LLM provider is not blocked.
+		for s := range input.Stream {
+			if t, ok := s.(llm.Text); ok && ctx.Err() == nil {
+				send(seg.Write(string(t)))
+			}
+		}
+		inputErr = input.Err
+
+		if inputErr == nil {
+			send(seg.Flush())
+		}
+	}()
+
+	go func() {
+		defer close(stream)
+		defer cancel()
+
+		var queue []string
+		var previous []*Chunk
+		open := true
+
+		for {
+			if len(queue) == 0 {
+				if !open {
+					break
+				}
+				s, ok := <-sentences
+				if !ok {
+					open = false
+					continue
+				}
+				queue = append(queue, s)
+			}
+
+			// Pick up the sentences that are already available to use them as the next text.
+		drain:
+			for open {
+				select {
+				case s, ok := <-sentences:
+					if !ok {
+						open = false
+						break drain
+					}
+					queue = append(queue, s)
+				default:
+					break drain
+				}
+			}
+
+			text := queue[0]
+			queue = queue[1:]
+
+			sc := &tts.SpeechContext{}
+			if len(queue) > 0 {
+				sc.NextText = queue[0]
+			}
+			texts := make([]string, 0, len(previous))
+			for _, p := range previous {
+				texts = append(texts, p.Text)
+				if p.Audio.RequestID != "" {
+					sc.PreviousRequestIDs = append(sc.PreviousRequestIDs, p.Audio.RequestID)
+				}
+			}
+			sc.PreviousText = strings.Join(texts, " ")
+
+			audio, err := tts.GenerateSpeechWithContext(ctx, m, text, sc)
+			if err != nil {
+				v.Err = err
+				return
+			}
+
+			chunk := &Chunk{Text: text, Audio: audio}
+			select {
+			case stream <- chunk:
+			case <-ctx.Done():
+				v.Err = ctx.Err()
+				return
+			}
+
+			previous = append(previous, chunk)
+			if len(previous) > maxContextChunks {
+				previous = previous[1:]
+			}
+		}
+
+		if inputErr != nil {
+			v.Err = inputErr
+		} else if err := ctx.Err(); err != nil {
+			v.Err = err
+		}
+	}()
+
+	return v
+}
@@ -0,0 +1,126 @@
+package speech_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/llmtools/speech"
+	"github.com/lemon-mint/coord/tts"
+)
+
+// recordingTTS synthesizes each text as its own bytes and records the speech contexts.
+// It fails with err when asked to synthesize failOn.
+type recordingTTS struct {
+	failOn string
+	err    error
+
+	mu       sync.Mutex
+	texts    []string
+	contexts []*tts.SpeechContext
+}
+
+func (m *recordingTTS) GenerateSpeech(ctx context.Context, text string) (*tts.AudioFile, error) {
+	return m.GenerateSpeechWithContext(ctx, text, nil)
+}
+
+func (m *recordingTTS) GenerateSpeechWithContext(ctx context.Context, text string, sc *tts.SpeechContext) (*tts.AudioFile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if text == m.failOn {
+		return nil, m.err
+	}
+
+	m.texts = append(m.texts, text)
+	m.contexts = append(m.contexts, sc)
+	return &tts.AudioFile{Format: tts.FormatMP3, Data: []byte(text), RequestID: "req-" + text}, nil
+}
+
+func llmStream(err error, parts ...string) *llm.StreamContent {
+	stream := make(chan llm.Segment, len(parts))
+	for _, p := range parts {
+		stream <- llm.Text(p)
+	}
+	close(stream)
+
+	return &llm.StreamContent{Stream: stream, Err: err}
+}
+
+var sentences = []string{"The first sentence is here.", "The second one follows it.", "And this is the third one."}
+
+func TestSpeak(t *testing.T) {
+	m := &recordingTTS{}
+	input := llmStream(nil, "The first sentence is here. The sec", "ond one follows it. And this", " is the third one.")
+
+	v := speech.Speak(context.Background(), m, input, nil)
+
+	var chunks []*speech.Chunk
+	for c := range v.Stream {
+		chunks = append(chunks, c)
+	}
+	if v.Err != nil {
+		t.Fatal(v.Err)
+	}
+
+	if len(chunks) != len(sentences) {
+		t.Fatalf("got %d chunks, want %d", len(chunks), len(sentences))
+	}
+	for i, c := range chunks {
+		if c.Text != sentences[i] || string(c.Audio.Data) != sentences[i] {
+			t.Errorf("chunk %d = %q (%q), want %q", i, c.Text, c.Audio.Data, sentences[i])
+		}
+	}
+
+	wantPrevious := []string{"", sentences[0], sentences[0] + " " + sentences[1]}
+	for i, sc := range m.contexts {
+		if sc.PreviousText != wantPrevious[i] {
+			t.Errorf("PreviousText of chunk %d = %q, want %q", i, sc.PreviousText, wantPrevious[i])
+		}
+		if len(sc.PreviousRequestIDs) != i || (i > 0 && sc.PreviousRequestIDs[i-1] != "req-"+sentences[i-1]) {
+			t.Errorf("PreviousRequestIDs of chunk %d = %v", i, sc.PreviousRequestIDs)
+		}
+		// The next sentence is only passed if it was already segmented when the chunk was synthesized.
+		if i+1 < len(sentences) && sc.NextText != "" && sc.NextText != sentences[i+1] {
+			t.Errorf("NextText of chunk %d = %q, want %q", i, sc.NextText, sentences[i+1])
+		}
+	}
+	if last := m.contexts[len(m.contexts)-1]; last.NextText != "" {
+		t.Errorf("NextText of the last chunk = %q, want empty", last.NextText)
+	}
+}
+
+func TestSpeakTTSError(t *testing.T) {
+	errTTS := errors.New("tts failed")
+	m := &recordingTTS{failOn: sentences[1], err: errTTS}
+
+	v := speech.Speak(context.Background(), m, llmStream(nil, sentences[0]+" "+sentences[1]+" "+sentences[2]), nil)
+
+	var chunks []*speech.Chunk
+	for c := range v.Stream {
+		chunks = append(chunks, c)
+	}
+
+	if !errors.Is(v.Err, errTTS) {
+		t.Errorf("err = %v, want %v", v.Err, errTTS)
+	}
+	if len(chunks) != 1 || chunks[0].Text != sentences[0] {
+		t.Errorf("chunks = %v, want only the first sentence", chunks)
+	}
+}
+
+func TestSpeakInputError(t *testing.T) {
+	m := &recordingTTS{}
+
+	v := speech.Speak(context.Background(), m, llmStream(llm.ErrOverloaded, sentences[0]+" "+sentences[1]), nil)
+
+	if err := v.Wait(); !errors.Is(err, llm.ErrOverloaded) {
+		t.Errorf("err = %v, want %v", err, llm.ErrOverloaded)
+	}
+	// The text that was not terminated before the failure is not synthesized.
+	if len(m.texts) != 1 || m.texts[0] != sentences[0] {
+		t.Errorf("synthesized = %v, want only the first sentence", m.texts)
+	}
+}
@@ -1,6 +1,8 @@
 package pconf
 
 import (
+	"github.com/lemon-mint/coord/llm"
+
 	"cloud.google.com/go/auth"
 	"google.golang.org/api/option"
 )
@@ -14,6 +16,8 @@ type GeneralConfig struct {
 
 	GoogleCredentials   *auth.Credentials
 	GoogleClientOptions []option.ClientOption
+
+	LLMInterceptors []llm.Interceptor // Wrap every model created by the LLM client, outermost first
 }
 
 func (GeneralConfig) String() string {
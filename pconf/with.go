@@ -1,6 +1,8 @@
 package pconf
 
 import (
+	"github.com/lemon-mint/coord/llm"
+
 	"cloud.google.com/go/auth"
 	"google.golang.org/api/option"
 )
@@ -68,3 +70,13 @@ func WithGoogleClientOptions(opts ...option.ClientOption) Config {
 		},
 	}
 }
+
+// WithLLMInterceptors adds interceptors that wrap every model created by the LLM client.
+func WithLLMInterceptors(interceptors ...llm.Interceptor) Config {
+	return &fnConf{
+		func(g *GeneralConfig) error {
+			g.LLMInterceptors = append(g.LLMInterceptors, interceptors...)
+			return nil
+		},
+	}
+}
@@ -2,75 +2,181 @@ package aistudio
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/lemon-mint/coord"
 	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/internal/embedutils"
+	"github.com/lemon-mint/coord/llm"
 	"github.com/lemon-mint/coord/pconf"
 	"github.com/lemon-mint/coord/provider"
 	"google.golang.org/genai"
 )
 
 var _ embedding.Model = (*textEmbedding)(nil)
+var _ embedding.BatchModel = (*textEmbedding)(nil)
+var _ embedding.ResultModel = (*textEmbedding)(nil)
+var _ embedding.ContentModel = (*textEmbedding)(nil)
+
+// aiStudioMaxBatchSize is the maximum number of inputs accepted by batchEmbedContents.
+const aiStudioMaxBatchSize = 100
 
 type textEmbedding struct {
 	client *genai.Client
 
-	model     string
-	outputDim int
+	model      string
+	outputDim  int
+	outputType embedding.OutputType
+	normalize  bool
 }
 
-func (g *textEmbedding) TextEmbedding(ctx context.Context, text string, task embedding.TaskType) ([]float64, error) {
-	config := &genai.EmbedContentConfig{}
-
-	var err error
-
+func convertTaskTypeGenerativeLanguage(task embedding.TaskType) (string, error) {
 	switch task {
 	case embedding.TaskTypeGeneral:
-		config.TaskType = ""
+		return "", nil
 	case embedding.TaskTypeSearchQuery:
-		config.TaskType = "RETRIEVAL_QUERY"
+		return "RETRIEVAL_QUERY", nil
 	case embedding.TaskTypeSearchDocument:
-		config.TaskType = "RETRIEVAL_DOCUMENT"
+		return "RETRIEVAL_DOCUMENT", nil
 	case embedding.TaskTypeSemanticSimilarity:
-		config.TaskType = "SEMANTIC_SIMILARITY"
+		return "SEMANTIC_SIMILARITY", nil
 	case embedding.TaskTypeClassification:
-		config.TaskType = "CLASSIFICATION"
+		return "CLASSIFICATION", nil
 	case embedding.TaskTypeClustering:
-		config.TaskType = "CLUSTERING"
+		return "CLUSTERING", nil
 	case embedding.TaskTypeQA:
-		config.TaskType = "QUESTION_ANSWERING"
+		return "QUESTION_ANSWERING", nil
 	case embedding.TaskTypeFactVerification:
-		config.TaskType = "FACT_VERIFICATION"
-	default:
-		return nil, embedding.ErrUnsupported
+		return "FACT_VERIFICATION", nil
 	}
 
-	response, err := g.client.Models.EmbedContent(ctx, g.model, []*genai.Content{
-		&genai.Content{
+	return "", embedding.ErrUnsupported
+}
+
+func (g *textEmbedding) embedContents(ctx context.Context, texts []string, task embedding.TaskType) (*embedding.Result, error) {
+	taskType, err := convertTaskTypeGenerativeLanguage(task)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &genai.EmbedContentConfig{
+		TaskType: taskType,
+	}
+
+	contents := make([]*genai.Content, len(texts))
+	for i := range texts {
+		contents[i] = &genai.Content{
 			Role: genai.RoleUser,
 			Parts: []*genai.Part{
-				&genai.Part{
-					Text: text,
+				{
+					Text: texts[i],
 				},
 			},
-		},
-	}, config)
+		}
+	}
+
+	response, err := g.client.Models.EmbedContent(ctx, g.model, contents, config)
+	if err != nil {
+		return nil, convertEmbeddingError(err)
+	}
+
+	if len(response.Embeddings) != len(texts) {
+		return nil, embedding.ErrNoResult
+	}
+
+	result := &embedding.Result{
+		Embeddings: make([]*embedding.Embedding, len(response.Embeddings)),
+	}
+
+	for i := range response.Embeddings {
+		embeddings := response.Embeddings[i].Values
+
+		if g.outputDim > 0 && len(embeddings) > g.outputDim {
+			embeddings = embeddings[:g.outputDim]
+		}
+
+		values := make([]float64, len(embeddings))
+		for j := range embeddings {
+			values[j] = float64(embeddings[j])
+		}
+
+		e := &embedding.Embedding{
+			Values: embedutils.Postprocess(values, g.normalize, g.outputType),
+		}
+
+		if stats := response.Embeddings[i].Statistics; stats != nil {
+			e.InputTokens = int(stats.TokenCount)
+			e.Truncated = stats.Truncated
+
+			if result.UsageData == nil {
+				result.UsageData = new(embedding.UsageData)
+			}
+			result.UsageData.InputTokens += e.InputTokens
+		}
+
+		result.Embeddings[i] = e
+	}
+
+	if response.Metadata != nil {
+		if result.UsageData == nil {
+			result.UsageData = new(embedding.UsageData)
+		}
+		result.UsageData.BillableCharacters = int(response.Metadata.BillableCharacterCount)
+	}
+
+	return result, nil
+}
+
+// convertEmbeddingError maps errors caused by over-long inputs to embedding.ErrMaxLengthExceeded
+// and other API errors to the sentinel error of their status code.
+func convertEmbeddingError(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == 400 && isMaxLengthMessage(apiErr.Message) {
+		return fmt.Errorf("%w: %w", embedding.ErrMaxLengthExceeded, err)
+	}
+
+	return convertErrorGenerativeLanguage(err, getEmbeddingErrorByStatus)
+}
+
+// isMaxLengthMessage reports whether an error message of the API is about an over-long input.
+func isMaxLengthMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "token") && (strings.Contains(msg, "exceed") || strings.Contains(msg, "limit"))
+}
+
+func (g *textEmbedding) TextEmbedding(ctx context.Context, text string, task embedding.TaskType) ([]float64, error) {
+	result, err := g.embedContents(ctx, []string{text}, task)
 	if err != nil {
 		return nil, err
 	}
 
-	embeddings := response.Embeddings[0].Values
+	return result.Embeddings[0].Values, nil
+}
 
-	if g.outputDim > 0 && len(embeddings) > g.outputDim {
-		embeddings = embeddings[:g.outputDim]
+func (g *textEmbedding) BatchTextEmbedding(ctx context.Context, texts []string, task embedding.TaskType) ([][]float64, error) {
+	result, err := g.EmbedTexts(ctx, texts, task)
+	if err != nil {
+		return nil, err
 	}
 
-	var output []float64 = make([]float64, len(embeddings))
-	for i := range embeddings {
-		output[i] = float64(embeddings[i])
+	return result.Values(), nil
+}
+
+func (g *textEmbedding) EmbedTexts(ctx context.Context, texts []string, task embedding.TaskType) (*embedding.Result, error) {
+	return embedutils.BatchResult(texts, aiStudioMaxBatchSize, func(texts []string) (*embedding.Result, error) {
+		return g.embedContents(ctx, texts, task)
+	})
+}
+
+func (g *textEmbedding) ContentEmbedding(ctx context.Context, parts []llm.Segment, task embedding.TaskType) ([]float64, error) {
+	text, err := embedding.TextContent(parts)
+	if err != nil {
+		return nil, err
 	}
 
-	return output, nil
+	return g.TextEmbedding(ctx, text, task)
 }
 
 var _ provider.EmbeddingClient = (*aiStudioClient)(nil)
@@ -80,10 +186,17 @@ func (g *aiStudioClient) NewEmbedding(model string, config *embedding.Config) (e
 		config = &embedding.Config{}
 	}
 
+	// The Gemini API always truncates over-long inputs and does not report it.
+	if config.AutoTruncate != nil && !*config.AutoTruncate {
+		return nil, fmt.Errorf("%w: disabling auto truncation is not supported by the Gemini API", embedding.ErrUnsupported)
+	}
+
 	_em := &textEmbedding{
-		client:    g.client,
-		model:     model,
-		outputDim: config.Dimension,
+		client:     g.client,
+		model:      model,
+		outputDim:  config.Dimension,
+		outputType: config.OutputType,
+		normalize:  config.Normalize,
 	}
 
 	return _em, nil
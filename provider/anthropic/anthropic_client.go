@@ -58,6 +58,13 @@ type anthropicSegment struct {
 	ToolUseID string             `json:"tool_use_id,omitempty"` // id for tool_result
 	Content   []anthropicSegment `json:"content,omitempty"`     // nested segments for tool_result
 	IsError   bool               `json:"is_error,omitempty"`    // true if the file is an error (used for tool_result)
+
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"` // cache breakpoint after the segment
+}
+
+type anthropicCacheControl struct {
+	Type string `json:"type"`          // "ephemeral"
+	TTL  string `json:"ttl,omitempty"` // "5m" or "1h" (default: "5m")
 }
 
 type anthropicFileData struct {
@@ -70,6 +77,14 @@ type anthropicTool struct {
 	Name        string      `json:"name"`         // Name of the tool
 	Description string      `json:"description"`  // Description of the tool
 	InputSchema *llm.Schema `json:"input_schema"` // Input schema for the tool
+
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"` // Cache breakpoint after the tool
+}
+
+type anthropicToolChoice struct {
+	Type                   string `json:"type"`                                // "auto", "any", "tool" or "none"
+	Name                   string `json:"name,omitempty"`                      // Name of the tool (used for tool)
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"` // Whether the model may use at most one tool
 }
 
 type anthropicThinking struct {
@@ -84,12 +99,13 @@ type anthropicCreateMessagesRequest struct {
 	Messages  []anthropicMessage `json:"messages"`   // List of messages to send to the model
 	MaxTokens int                `json:"max_tokens"` // Maximum number of tokens to generate
 
-	SystemPrompt  string                           `json:"system,omitempty"`         // System prompt for the model
+	SystemPrompt  []anthropicSegment               `json:"system,omitempty"`         // System prompt for the model (text segments)
 	MetaData      *anthropicCreateMessagesMetaData `json:"metadata,omitempty"`       // Metadata for the request
 	StopSequences []string                         `json:"stop_sequences,omitempty"` // List of stop sequences for the model
 
-	Thinking *anthropicThinking `json:"thinking,omitempty"` // Thinking configuration
-	Tools    []anthropicTool    `json:"tools,omitempty"`    // List of tools to use in the conversation
+	Thinking   *anthropicThinking   `json:"thinking,omitempty"`    // Thinking configuration
+	Tools      []anthropicTool      `json:"tools,omitempty"`       // List of tools to use in the conversation
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"` // How the model should use the tools
 
 	Temperature *float32 `json:"temperature,omitempty"` // Temperature parameter for the model
 	TopP        *float32 `json:"top_p,omitempty"`       // Top-p parameter for the model
@@ -113,8 +129,10 @@ const (
 )
 
 type anthropicUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 }
 
 type anthropicCreateMessagesResponse struct {
@@ -43,7 +43,7 @@ func getErrorByStatus(err_c int) error {
 	case 500:
 		// api_error
 		return llm.ErrInternalServer
-	case 529:
+	case 502, 503, 504, 529:
 		// overloaded_error
 		return llm.ErrOverloaded
 	}
@@ -7,11 +7,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/lemon-mint/coord"
 	"github.com/lemon-mint/coord/internal/llmutils"
+	"github.com/lemon-mint/coord/internal/retryafter"
 	"github.com/lemon-mint/coord/llm"
 	"github.com/lemon-mint/coord/pconf"
 	"github.com/lemon-mint/coord/provider"
@@ -51,7 +54,8 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 		Content: &llm.Content{},
 	}
 
-	msgs := convertContextAnthropic(chat)
+	history, input := llmutils.PairFunctionResponses(chat.Contents, input)
+	msgs := convertContextAnthropic(history)
 	msgs = append(msgs, convertContentAnthropic(input))
 
 	go func() {
@@ -63,10 +67,12 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 			return
 		}
 
+		var response anthropicCreateMessagesResponse
+
 		model_request := &anthropicCreateMessagesRequest{
 			Model:         g.model,
 			Messages:      msgs,
-			SystemPrompt:  g.config.SystemInstruction + chat.SystemInstruction,
+			SystemPrompt:  convertSystemAnthropic(g.config.SystemInstruction+chat.SystemInstruction, chat.SystemCacheControl),
 			StopSequences: g.config.StopSequences,
 			Tools:         convertToolsAnthropic(chat.Tools),
 			Temperature:   g.config.Temperature,
@@ -81,6 +87,48 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 			model_request.MaxTokens = *g.config.MaxOutputTokens
 		}
 
+		if chat.ToolChoice != nil && len(model_request.Tools) > 0 {
+			model_request.ToolChoice = convertToolChoiceAnthropic(chat.ToolChoice)
+		}
+
+		// Structured output is implemented by forcing the model to call a tool
+		// that takes the response as its input.
+		var formatTool string
+		var formatWrapped bool
+		if g.config.ResponseFormat != nil {
+			// Anthropic only accepts the auto and none tool choices with extended thinking,
+			// so the response format tool can not be forced.
+			if g.config.ThinkingConfig != nil && g.config.ThinkingConfig.ThinkingBudget != nil {
+				v.Err = fmt.Errorf("%w: response format can not be combined with extended thinking", llm.ErrInvalidRequest)
+				return
+			}
+
+			tool, wrapped := responseFormatTool(g.config.ResponseFormat)
+			formatTool, formatWrapped = tool.Name, wrapped
+
+			// The model is forced to call the format tool if it must not call the caller's tools.
+			// Otherwise it must call any tool, unless the caller chose how the tools are used.
+			switch {
+			case len(model_request.Tools) == 0 || model_request.ToolChoice != nil && model_request.ToolChoice.Type == "none":
+				model_request.ToolChoice = &anthropicToolChoice{Type: "tool", Name: tool.Name}
+			case model_request.ToolChoice == nil:
+				model_request.ToolChoice = &anthropicToolChoice{Type: "any"}
+			}
+			model_request.Tools = append(model_request.Tools, tool)
+		}
+		isFormat := func(index int) bool {
+			return formatTool != "" && response.Content[index].Type == anthropicSegmentToolUse && response.Content[index].Name == formatTool
+		}
+
+		if p := g.config.ParallelToolCalls; p != nil && !*p && len(model_request.Tools) > 0 {
+			if model_request.ToolChoice == nil {
+				model_request.ToolChoice = &anthropicToolChoice{Type: "auto"}
+			}
+			if model_request.ToolChoice.Type != "none" {
+				model_request.ToolChoice.DisableParallelToolUse = true
+			}
+		}
+
 		if g.config.ThinkingConfig != nil {
 			if g.config.ThinkingConfig.ThinkingBudget != nil {
 				model_request.Thinking = &anthropicThinking{
@@ -116,13 +164,12 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			v.Err = getErrorByStatus(resp.StatusCode)
+			v.Err = retryafter.Wrap(getErrorByStatus(resp.StatusCode), resp.Header)
 			return
 		}
 
 		br := bufio.NewScanner(resp.Body)
 		var parser fastjson.Parser
-		var response anthropicCreateMessagesResponse
 
 	L:
 		for {
@@ -194,6 +241,8 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 					}
 					response.Usage.InputTokens += message.Get("usage").Get("input_tokens").GetInt()
 					response.Usage.OutputTokens += message.Get("usage").Get("output_tokens").GetInt()
+					response.Usage.CacheCreationInputTokens += message.Get("usage").Get("cache_creation_input_tokens").GetInt()
+					response.Usage.CacheReadInputTokens += message.Get("usage").Get("cache_read_input_tokens").GetInt()
 
 					for _, content := range ae.GetArray("content") {
 						var c anthropicSegment
@@ -230,6 +279,8 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 					}
 					response.Usage.InputTokens += ae.Get("usage").Get("input_tokens").GetInt()
 					response.Usage.OutputTokens += ae.Get("usage").Get("output_tokens").GetInt()
+					response.Usage.CacheCreationInputTokens += ae.Get("usage").Get("cache_creation_input_tokens").GetInt()
+					response.Usage.CacheReadInputTokens += ae.Get("usage").Get("cache_read_input_tokens").GetInt()
 
 				case "content_block_start":
 					// {
@@ -322,6 +373,14 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 					case anthropicSegmentInputJSONDelta:
 						if len(c.InputJSON) > 0 {
 							response.Content[index].InputJSON = append(response.Content[index].InputJSON, c.InputJSON...)
+							if isFormat(index) && !formatWrapped {
+								select {
+								case stream <- llm.Text(c.InputJSON):
+								case <-ctx.Done():
+									v.Err = ctx.Err()
+									return
+								}
+							}
 						}
 					case anthropicSegmentThinkingDelta:
 						if len(c.Thinking) > 0 {
@@ -368,6 +427,18 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 							return
 						}
 
+						if isFormat(index) {
+							if formatWrapped {
+								select {
+								case stream <- llm.Text(responseFormatText(response.Content[index], true)):
+								case <-ctx.Done():
+									v.Err = ctx.Err()
+									return
+								}
+							}
+							break
+						}
+
 						select {
 						case stream <- &llm.FunctionCall{
 							Name: response.Content[index].Name,
@@ -395,14 +466,34 @@ func (g *anthropicModel) GenerateStream(ctx context.Context, chat *llm.ChatConte
 			}
 		}
 
+		if formatTool != "" {
+			for i := range response.Content {
+				if isFormat(i) {
+					response.Content[i] = anthropicSegment{
+						Type: anthropicSegmentText,
+						Text: responseFormatText(response.Content[i], formatWrapped),
+					}
+				}
+			}
+			if response.StopReason == string(StopToolUse) {
+				response.StopReason = string(StopEndTurn)
+			}
+		}
+
 		v.Content = convertAnthropicContent(response)
 		v.Content.Parts = llmutils.Normalize(v.Content.Parts)
 		v.FinishReason = convertAnthropicFinishReason(response.StopReason)
+		v.Model = response.Model
+		v.ResponseID = response.ID
 		if response.Usage != nil {
+			// Anthropic reports cached input tokens separately from input_tokens.
+			input_tokens := response.Usage.InputTokens + response.Usage.CacheCreationInputTokens + response.Usage.CacheReadInputTokens
 			v.UsageData = &llm.UsageData{
-				InputTokens:  response.Usage.InputTokens,
-				OutputTokens: response.Usage.OutputTokens,
-				TotalTokens:  response.Usage.InputTokens + response.Usage.OutputTokens,
+				InputTokens:      input_tokens,
+				OutputTokens:     response.Usage.OutputTokens,
+				TotalTokens:      input_tokens + response.Usage.OutputTokens,
+				CacheReadTokens:  response.Usage.CacheReadInputTokens,
+				CacheWriteTokens: response.Usage.CacheCreationInputTokens,
 			}
 		}
 	}()
@@ -494,9 +585,38 @@ func convertContentAnthropic(s *llm.Content) anthropicMessage {
 		m.Content = append(m.Content, a)
 	}
 
+	if s.CacheControl != nil && len(m.Content) > 0 {
+		m.Content[len(m.Content)-1].CacheControl = convertCacheControlAnthropic(s.CacheControl)
+	}
+
 	return m
 }
 
+func convertCacheControlAnthropic(c *llm.CacheControl) *anthropicCacheControl {
+	if c == nil {
+		return nil
+	}
+
+	cc := &anthropicCacheControl{Type: "ephemeral"}
+	if c.TTL > 5*time.Minute {
+		// Anthropic supports lifetimes of 5 minutes and 1 hour.
+		cc.TTL = "1h"
+	}
+	return cc
+}
+
+func convertSystemAnthropic(system string, c *llm.CacheControl) []anthropicSegment {
+	if system == "" {
+		return nil
+	}
+
+	return []anthropicSegment{{
+		Type:         anthropicSegmentText,
+		Text:         system,
+		CacheControl: convertCacheControlAnthropic(c),
+	}}
+}
+
 func convertAnthropicContent(chat anthropicCreateMessagesResponse) *llm.Content {
 	var role llm.Role
 	switch chat.Role {
@@ -546,11 +666,11 @@ L:
 	}
 }
 
-func convertContextAnthropic(c *llm.ChatContext) []anthropicMessage {
-	var contents []anthropicMessage = make([]anthropicMessage, len(c.Contents))
+func convertContextAnthropic(c []*llm.Content) []anthropicMessage {
+	var contents []anthropicMessage = make([]anthropicMessage, len(c))
 
-	for i := range c.Contents {
-		contents[i] = convertContentAnthropic(c.Contents[i])
+	for i := range c {
+		contents[i] = convertContentAnthropic(c[i])
 	}
 
 	return contents
@@ -561,15 +681,124 @@ func convertToolsAnthropic(c []*llm.FunctionDeclaration) []anthropicTool {
 
 	for i := range c {
 		tools[i] = anthropicTool{
-			Name:        c[i].Name,
-			Description: c[i].Description,
-			InputSchema: c[i].Schema,
+			Name:         c[i].Name,
+			Description:  c[i].Description,
+			InputSchema:  c[i].Schema,
+			CacheControl: convertCacheControlAnthropic(c[i].CacheControl),
 		}
 	}
 
 	return tools
 }
 
+func convertToolChoiceAnthropic(t *llm.ToolChoice) *anthropicToolChoice {
+	if t.Name != "" {
+		return &anthropicToolChoice{Type: "tool", Name: t.Name}
+	}
+
+	switch t.Effective() {
+	case llm.ToolChoiceNone:
+		return &anthropicToolChoice{Type: "none"}
+	case llm.ToolChoiceRequired:
+		return &anthropicToolChoice{Type: "any"}
+	}
+
+	return &anthropicToolChoice{Type: "auto"}
+}
+
+const responseFormatDescription = "Respond to the user with the input of this tool."
+
+// responseFormatTool returns the tool whose input is the response in the format.
+// Tool inputs must be objects, so other schemas are wrapped in the "value" property of an object.
+func responseFormatTool(rf *llm.ResponseFormat) (tool anthropicTool, wrapped bool) {
+	tool = anthropicTool{
+		Name:        rf.FormatName(),
+		Description: rf.Description,
+		InputSchema: rf.Schema,
+	}
+
+	if tool.Description == "" {
+		tool.Description = responseFormatDescription
+	}
+
+	switch {
+	case rf.Schema == nil:
+		tool.InputSchema = &llm.Schema{Type: llm.OpenAPITypeObject}
+	case rf.Schema.Type != llm.OpenAPITypeObject:
+		// The definitions must stay on the root, and references to the root move with the schema.
+		value := rewriteRootRefs(rf.Schema, "#/properties/value")
+		tool.InputSchema = &llm.Schema{
+			Type:       llm.OpenAPITypeObject,
+			Properties: map[string]*llm.Schema{"value": value},
+			Required:   []string{"value"},
+			Defs:       value.Defs,
+		}
+		value.Defs = nil
+		wrapped = true
+	}
+
+	return tool, wrapped
+}
+
+// rewriteRootRefs returns a copy of the schema in which the references to the root schema ("#") are replaced by ref.
+func rewriteRootRefs(s *llm.Schema, ref string) *llm.Schema {
+	if s == nil {
+		return nil
+	}
+
+	c := *s
+	if c.Ref == "#" {
+		c.Ref = ref
+	}
+
+	rewriteMap := func(m map[string]*llm.Schema) map[string]*llm.Schema {
+		if m == nil {
+			return nil
+		}
+		r := make(map[string]*llm.Schema, len(m))
+		for k, v := range m {
+			r[k] = rewriteRootRefs(v, ref)
+		}
+		return r
+	}
+	rewriteSlice := func(l []*llm.Schema) []*llm.Schema {
+		if l == nil {
+			return nil
+		}
+		r := make([]*llm.Schema, len(l))
+		for i := range l {
+			r[i] = rewriteRootRefs(l[i], ref)
+		}
+		return r
+	}
+
+	c.Properties = rewriteMap(c.Properties)
+	c.Defs = rewriteMap(c.Defs)
+	c.Items = rewriteRootRefs(c.Items, ref)
+	c.AnyOf = rewriteSlice(c.AnyOf)
+	c.OneOf = rewriteSlice(c.OneOf)
+	if c.AdditionalProperties != nil && c.AdditionalProperties.Schema != nil {
+		ap := *c.AdditionalProperties
+		ap.Schema = rewriteRootRefs(ap.Schema, ref)
+		c.AdditionalProperties = &ap
+	}
+
+	return &c
+}
+
+// responseFormatText returns the JSON response from the input of the response format tool.
+func responseFormatText(seg anthropicSegment, wrapped bool) string {
+	if !wrapped {
+		return string(seg.InputJSON)
+	}
+
+	var input map[string]json.RawMessage
+	if err := json.Unmarshal(seg.InputJSON, &input); err != nil {
+		return string(seg.InputJSON)
+	}
+	return string(input["value"])
+}
+
 func convertAnthropicFinishReason(stop_reason string) llm.FinishReason {
 	switch stop_reason {
 	case "end_turn":
@@ -2,7 +2,11 @@ package anthropic_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -391,3 +395,60 @@ L:
 		return
 	}
 }
+
+type treeNode struct {
+	Name     string     `json:"name"`
+	Children []treeNode `json:"children"`
+}
+
+func TestAnthropicResponseFormatWrappedDefs(t *testing.T) {
+	schema, err := llm.SchemaFor[[]treeNode]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- body
+		http.Error(w, `{"type":"error","error":{"type":"invalid_request_error","message":"stop"}}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := anthropic.Provider.NewLLMClient(context.Background(), pconf.WithAPIKey("test"), pconf.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	model, err := client.NewLLM("claude-sonnet-4-0", &llm.Config{ResponseFormat: &llm.ResponseFormat{Schema: schema}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.GenerateStream(context.Background(), &llm.ChatContext{}, llm.TextContent(llm.RoleUser, "Hello!")).Wait()
+
+	var request struct {
+		Tools []struct {
+			InputSchema *llm.Schema `json:"input_schema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(<-requests, &request); err != nil {
+		t.Fatal(err)
+	}
+	if len(request.Tools) != 1 {
+		t.Fatalf("got %d tools, want 1", len(request.Tools))
+	}
+
+	root := request.Tools[0].InputSchema
+	value := root.Properties["value"]
+	if value == nil || value.Type != llm.OpenAPITypeArray || value.Defs != nil {
+		t.Fatalf("unexpected value schema: %+v", value)
+	}
+	ref := value.Items.Properties["children"].Items.Ref
+	if def := root.Resolve(ref); def == nil || def.Properties["children"].Items.Ref != ref {
+		t.Errorf("the definitions were not moved to the root: %+v", root)
+	}
+	if err := root.Validate(map[string]any{"value": []any{map[string]any{"name": "a", "children": []any{}}}}); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/lemon-mint/coord/internal/retryafter"
 )
 
 type ttsRequest struct {
@@ -17,10 +20,10 @@ type ttsRequest struct {
 	VoiceSettings                   TtsVoiceSettings                     `json:"voice_settings"`
 	PronunciationDictionaryLocators []TtsPronunciationDictionaryLocators `json:"pronunciation_dictionary_locators"`
 	Seed                            int                                  `json:"seed"`
-	PreviousText                    string                               `json:"previous_text"`
-	NextText                        string                               `json:"next_text"`
-	PreviousRequestIds              []string                             `json:"previous_request_ids"`
-	NextRequestIds                  []string                             `json:"next_request_ids"`
+	PreviousText                    string                               `json:"previous_text,omitempty"`
+	NextText                        string                               `json:"next_text,omitempty"`
+	PreviousRequestIds              []string                             `json:"previous_request_ids,omitempty"`
+	NextRequestIds                  []string                             `json:"next_request_ids,omitempty"`
 }
 
 type TtsVoiceSettings struct {
@@ -35,6 +38,21 @@ type TtsPronunciationDictionaryLocators struct {
 	VersionID                 string `json:"version_id"`
 }
 
+type sttWord struct {
+	Text      string  `json:"text"`
+	Type      string  `json:"type"` // word, spacing or audio_event
+	Start     float64 `json:"start"`
+	End       float64 `json:"end"`
+	SpeakerID string  `json:"speaker_id"`
+}
+
+type sttResponse struct {
+	LanguageCode        string    `json:"language_code"`
+	LanguageProbability float64   `json:"language_probability"`
+	Text                string    `json:"text"`
+	Words               []sttWord `json:"words"`
+}
+
 type VoiceListResponse struct {
 	Voices []struct {
 		VoiceID    string      `json:"voice_id"`
@@ -98,36 +116,122 @@ type elevenlabsAPIClient struct {
 
 const elevenlabsBaseURL = "https://api.elevenlabs.io/v1"
 
-func (c *elevenlabsAPIClient) RequestTTS(ctx context.Context, voiceid string, req ttsRequest) ([]byte, error) {
+// RequestTTS returns the synthesized audio and the ID of the request.
+func (c *elevenlabsAPIClient) RequestTTS(ctx context.Context, voiceid string, outputFormat string, req ttsRequest) ([]byte, string, error) {
 	url, err := url.JoinPath(c.baseURL, "/text-to-speech/"+voiceid)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	url += "?output_format=" + outputFormat
 
 	data, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	r, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
 
 	if err := c.authHandler(r); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", retryafter.Wrap(getErrorByStatus(resp.StatusCode), resp.Header)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, resp.Header.Get("request-id"), nil
+}
+
+func (c *elevenlabsAPIClient) RequestSTT(ctx context.Context, fields map[string]string, filename string, file []byte) (*sttResponse, error) {
+	url, err := url.JoinPath(c.baseURL, "/speech-to-text")
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return nil, err
+		}
+	}
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fw.Write(file); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	r, _ := http.NewRequestWithContext(ctx, "POST", url, &body)
+
+	if err := c.authHandler(r); err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := c.httpClient.Do(r)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, getErrorByStatus(resp.StatusCode)
+		return nil, retryafter.Wrap(getSTTErrorByStatus(resp.StatusCode), resp.Header)
 	}
 
-	body, _ := io.ReadAll(resp.Body)
+	var sttResp sttResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sttResp); err != nil {
+		return nil, err
+	}
+
+	return &sttResp, nil
+}
+
+// RequestTTSStream requests the streaming variant of the endpoint. The caller must close the returned body.
+func (c *elevenlabsAPIClient) RequestTTSStream(ctx context.Context, voiceid string, outputFormat string, req ttsRequest) (io.ReadCloser, error) {
+	url, err := url.JoinPath(c.baseURL, "/text-to-speech/"+voiceid+"/stream")
+	if err != nil {
+		return nil, err
+	}
+	url += "?output_format=" + outputFormat
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+
+	if err := c.authHandler(r); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, retryafter.Wrap(getErrorByStatus(resp.StatusCode), resp.Header)
+	}
 
-	return body, nil
+	return resp.Body, nil
 }
 
 func (c *elevenlabsAPIClient) RequestVoiceList(ctx context.Context) ([]Voice, error) {
@@ -149,7 +253,7 @@ func (c *elevenlabsAPIClient) RequestVoiceList(ctx context.Context) ([]Voice, er
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, getErrorByStatus(resp.StatusCode)
+		return nil, retryafter.Wrap(getErrorByStatus(resp.StatusCode), resp.Header)
 	}
 
 	body, _ := io.ReadAll(resp.Body)
@@ -2,9 +2,8 @@ package elevenlabs
 
 import (
 	"errors"
-	"fmt"
 
-	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/stt"
 	"github.com/lemon-mint/coord/tts"
 )
 
@@ -17,10 +16,45 @@ var (
 
 func getErrorByStatus(err_c int) error {
 	switch err_c {
+	case 400:
+		return tts.ErrInvalidRequest
+	case 401:
+		return tts.ErrAuthentication
+	case 403:
+		return tts.ErrPermission
+	case 404:
+		return tts.ErrNotFound
 	case 422:
 		return tts.ErrUnprocessableContent
+	case 429:
+		return tts.ErrRateLimit
+	case 500:
+		return tts.ErrInternalServer
+	case 502, 503, 504:
+		return tts.ErrOverloaded
 	}
 
-	fmt.Printf("Unknown error code: %d\n", err_c)
-	return llm.ErrUnknown
+	return tts.ErrUnknown
+}
+
+func getSTTErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		return stt.ErrInvalidRequest
+	case 401:
+		return stt.ErrAuthentication
+	case 403:
+		return stt.ErrPermission
+	case 404:
+		return stt.ErrNotFound
+	case 422:
+		return stt.ErrUnprocessableContent
+	case 429:
+		return stt.ErrRateLimit
+	case 500:
+		return stt.ErrInternalServer
+	case 503:
+		return stt.ErrOverloaded
+	}
+	return stt.ErrUnknown
 }
@@ -0,0 +1,184 @@
+package elevenlabs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/provider"
+	"github.com/lemon-mint/coord/stt"
+	"github.com/lemon-mint/coord/tts"
+)
+
+const defaultSTTModel = "scribe_v1"
+
+// =================== Client ===================
+
+var _ provider.STTClient = (*ElevenlabsClient)(nil)
+
+func (g *ElevenlabsClient) NewSTT(model string, config *stt.Config) (stt.Model, error) {
+	if config == nil {
+		config = &stt.Config{}
+	}
+
+	if model == "" {
+		model = defaultSTTModel
+	}
+
+	_sm := &elevenlabsSTTModel{
+		client:   g.client,
+		model:    model,
+		language: config.Language,
+
+		sample_rate: config.SampleRate,
+	}
+
+	return _sm, nil
+}
+
+// =================== Model ===================
+
+var _ stt.Model = (*elevenlabsSTTModel)(nil)
+
+type elevenlabsSTTModel struct {
+	client *elevenlabsAPIClient
+
+	model    string
+	language string
+
+	sample_rate int
+}
+
+func (g *elevenlabsSTTModel) Transcribe(ctx context.Context, audio *tts.AudioFile) (*stt.Transcription, error) {
+	switch audio.Format {
+	case tts.FormatMP3, tts.FormatOGG, tts.FormatAAC, tts.FormatFLAC, tts.FormatWAV:
+	case tts.FormatLINEAR16, tts.FormatMULAW, tts.FormatALAW:
+		// Raw audio is uploaded as a WAV file.
+		if audio.SampleRate == 0 {
+			raw := *audio
+			raw.SampleRate = g.sample_rate
+			audio = &raw
+		}
+		wav, err := tts.Convert(audio, tts.FormatWAV, 0)
+		if err != nil {
+			return nil, err
+		}
+		audio = wav
+	default:
+		return nil, stt.ErrUnsupportedFileFormat
+	}
+
+	fields := map[string]string{
+		"model_id":               g.model,
+		"timestamps_granularity": "word",
+		"diarize":                "true",
+	}
+	if g.language != "" {
+		fields["language_code"] = g.language
+	}
+
+	resp, err := g.client.RequestSTT(ctx, fields, "audio"+audio.Format.Extension(), audio.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stt.Transcription{
+		Text:     strings.TrimSpace(resp.Text),
+		Language: resp.LanguageCode,
+		Segments: groupWords(resp.Words),
+	}, nil
+}
+
+// groupWords joins the words of the transcript into segments, which end at
+// sentence boundaries and speaker changes.
+func groupWords(words []sttWord) []stt.Segment {
+	var segments []stt.Segment
+	var text strings.Builder
+	var current stt.Segment
+	var open bool
+
+	flush := func() {
+		if !open {
+			return
+		}
+		current.Text = strings.TrimSpace(text.String())
+		if current.Text != "" {
+			segments = append(segments, current)
+		}
+		text.Reset()
+		open = false
+	}
+
+	for _, w := range words {
+		if w.Type == "spacing" {
+			if open {
+				text.WriteString(w.Text)
+			}
+			continue
+		}
+
+		if open && w.SpeakerID != current.Speaker {
+			flush()
+		}
+
+		if !open {
+			current = stt.Segment{
+				Start:   time.Duration(w.Start * float64(time.Second)),
+				Speaker: w.SpeakerID,
+			}
+			open = true
+		}
+		text.WriteString(w.Text)
+		current.End = time.Duration(w.End * float64(time.Second))
+
+		if strings.HasSuffix(w.Text, ".") || strings.HasSuffix(w.Text, "?") || strings.HasSuffix(w.Text, "!") {
+			flush()
+		}
+	}
+	flush()
+
+	return segments
+}
+
+// =================== Provider ===================
+
+var _ provider.STTProvider = Provider
+
+func (ElevenlabsProvider) NewSTTClient(ctx context.Context, configs ...pconf.Config) (provider.STTClient, error) {
+	client_config := pconf.GeneralConfig{}
+	for i := range configs {
+		configs[i].Apply(&client_config)
+	}
+
+	apiKey := client_config.APIKey
+
+	if apiKey == "" {
+		return nil, ErrAPIKeyRequired
+	}
+
+	_elevenlabsClient, err := newClient(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ElevenlabsClient{
+		client: _elevenlabsClient,
+	}, nil
+}
+
+// ===================== Init =====================
+
+func init() {
+	var exists bool
+	for _, n := range coord.ListSTTProviders() {
+		if n == ProviderName {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		coord.RegisterSTTProvider(ProviderName, Provider)
+	}
+}
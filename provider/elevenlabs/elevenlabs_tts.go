@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"math/rand"
+	"slices"
+	"strconv"
 
 	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/internal/ttsutils"
 	"github.com/lemon-mint/coord/pconf"
 	"github.com/lemon-mint/coord/provider"
 	"github.com/lemon-mint/coord/tts"
@@ -68,7 +71,7 @@ type elevenlabsModel struct {
 	config *tts.Config
 }
 
-func (g *elevenlabsModel) GenerateSpeech(ctx context.Context, text string) (*tts.AudioFile, error) {
+func (g *elevenlabsModel) request(text string) ttsRequest {
 	reqData := ttsRequest{
 		ModelID: g.config.Model,
 		Text:    text,
@@ -97,15 +100,112 @@ func (g *elevenlabsModel) GenerateSpeech(ctx context.Context, text string) (*tts
 		reqData.Seed = rand.Int()
 	}
 
-	voice, err := g.client.RequestTTS(ctx, g.config.VoiceID, reqData)
+	return reqData
+}
+
+// pcmSampleRates are the sample rates of the pcm output formats.
+var pcmSampleRates = []int{8000, 16000, 22050, 24000, 44100}
+
+// native returns the output format requested from the api, the matching tts.Format and its sample rate.
+// Formats and sample rates without a native equivalent are converted from pcm.
+func (g *elevenlabsModel) native() (string, tts.Format, int, error) {
+	rate := g.config.SampleRate
+
+	switch g.config.Format {
+	case "", tts.FormatMP3:
+		return "mp3_44100_128", tts.FormatMP3, 0, nil
+	case tts.FormatMULAW:
+		if rate == 0 || rate == 8000 {
+			return "ulaw_8000", tts.FormatMULAW, 8000, nil
+		}
+	case tts.FormatALAW:
+		if rate == 0 {
+			rate = 8000
+		}
+	case tts.FormatLINEAR16, tts.FormatWAV:
+	default:
+		return "", "", 0, tts.ErrUnsupportedFileFormat
+	}
+
+	if !slices.Contains(pcmSampleRates, rate) {
+		rate = 24000
+	}
+
+	return "pcm_" + strconv.Itoa(rate), tts.FormatLINEAR16, rate, nil
+}
+
+// convert reports whether the native output has to be converted to the configured format.
+func (g *elevenlabsModel) convert(native tts.Format, rate int) bool {
+	if g.config.Format == "" || native == tts.FormatMP3 {
+		return false
+	}
+
+	return native != g.config.Format || (g.config.SampleRate != 0 && g.config.SampleRate != rate)
+}
+
+func (g *elevenlabsModel) GenerateSpeech(ctx context.Context, text string) (*tts.AudioFile, error) {
+	return g.GenerateSpeechWithContext(ctx, text, nil)
+}
+
+// maxPreviousRequestIDs is the maximum number of previous request ids accepted by the API.
+const maxPreviousRequestIDs = 3
+
+var _ tts.ContextModel = (*elevenlabsModel)(nil)
+
+func (g *elevenlabsModel) GenerateSpeechWithContext(ctx context.Context, text string, sc *tts.SpeechContext) (*tts.AudioFile, error) {
+	reqData := g.request(text)
+	if sc != nil {
+		reqData.PreviousText = sc.PreviousText
+		reqData.NextText = sc.NextText
+		reqData.PreviousRequestIds = sc.PreviousRequestIDs
+		if len(reqData.PreviousRequestIds) > maxPreviousRequestIDs {
+			reqData.PreviousRequestIds = reqData.PreviousRequestIds[len(reqData.PreviousRequestIds)-maxPreviousRequestIDs:]
+		}
+	}
+
+	outputFormat, native, rate, err := g.native()
 	if err != nil {
 		return nil, err
 	}
 
-	return &tts.AudioFile{
-		Format: tts.FormatMP3,
-		Data:   voice,
-	}, nil
+	voice, requestID, err := g.client.RequestTTS(ctx, g.config.VoiceID, outputFormat, reqData)
+	if err != nil {
+		return nil, err
+	}
+
+	audio := &tts.AudioFile{
+		Format:     native,
+		Data:       voice,
+		SampleRate: rate,
+		RequestID:  requestID,
+	}
+
+	if g.convert(native, rate) {
+		return tts.Convert(audio, g.config.Format, g.config.SampleRate)
+	}
+
+	return audio, nil
+}
+
+var _ tts.StreamModel = (*elevenlabsModel)(nil)
+
+func (g *elevenlabsModel) GenerateSpeechStream(ctx context.Context, text string) *tts.AudioStream {
+	outputFormat, native, rate, err := g.native()
+	if err != nil {
+		return ttsutils.ErrorStream(err)
+	}
+
+	if g.convert(native, rate) {
+		// Converted audio is delivered as a single chunk.
+		return tts.GenerateSpeechStream(ctx, struct{ tts.Model }{g}, text)
+	}
+
+	body, err := g.client.RequestTTSStream(ctx, g.config.VoiceID, outputFormat, g.request(text))
+	if err != nil {
+		return ttsutils.ErrorStream(err)
+	}
+
+	return ttsutils.ReaderStream(ctx, native, body)
 }
 
 // =================== Provider ===================
@@ -2,6 +2,8 @@ package openai
 
 import (
 	"errors"
+	"net/http"
+
 	"github.com/lemon-mint/coord/pconf"
 	"github.com/sashabaranov/go-openai"
 )
@@ -28,10 +30,14 @@ func WithAzureConfig(apiKey, baseURL string) pconf.Config {
 	return WithOpenAIConfig(openai.DefaultAzureConfig(apiKey, baseURL))
 }
 
+// WithOpenAIConfig creates the client with the config. The HTTP client of the config is
+// wrapped to read the Retry-After header of failed requests.
 func WithOpenAIConfig(config openai.ClientConfig) pconf.Config {
-	return WithOpenAIClient(openai.NewClientWithConfig(config))
+	return WithOpenAIClient(newOpenAIClient(config))
 }
 
+// WithOpenAIClient uses the client as is, so the errors of its requests do not report the
+// Retry-After header.
 func WithOpenAIClient(client *openai.Client) pconf.Config {
 	return openaiConfig(func(c *openAIClient) error {
 		c.client = client
@@ -66,6 +72,14 @@ func newClient(configs ...pconf.Config) (*openAIClient, error) {
 		openai_config.BaseURL = client_config.BaseURL
 	}
 
-	openai_client.client = openai.NewClientWithConfig(openai_config)
+	openai_client.client = newOpenAIClient(openai_config)
 	return &openai_client, nil
 }
+
+func newOpenAIClient(config openai.ClientConfig) *openai.Client {
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	config.HTTPClient = retryAfterDoer{next: config.HTTPClient}
+	return openai.NewClientWithConfig(config)
+}
@@ -0,0 +1,122 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lemon-mint/coord/internal/retryafter"
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/stt"
+	"github.com/lemon-mint/coord/tts"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func getErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		return llm.ErrInvalidRequest
+	case 401:
+		return llm.ErrAuthentication
+	case 403:
+		return llm.ErrPermission
+	case 404:
+		return llm.ErrNotFound
+	case 429:
+		return llm.ErrRateLimit
+	case 500:
+		return llm.ErrInternalServer
+	case 502, 503, 504:
+		return llm.ErrOverloaded
+	}
+	return llm.ErrUnknown
+}
+
+func getTTSErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		return tts.ErrInvalidRequest
+	case 401:
+		return tts.ErrAuthentication
+	case 403:
+		return tts.ErrPermission
+	case 404:
+		return tts.ErrNotFound
+	case 429:
+		return tts.ErrRateLimit
+	case 500:
+		return tts.ErrInternalServer
+	case 502, 503, 504:
+		return tts.ErrOverloaded
+	}
+	return tts.ErrUnknown
+}
+
+func getSTTErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		return stt.ErrInvalidRequest
+	case 401:
+		return stt.ErrAuthentication
+	case 403:
+		return stt.ErrPermission
+	case 404:
+		return stt.ErrNotFound
+	case 429:
+		return stt.ErrRateLimit
+	case 500:
+		return stt.ErrInternalServer
+	case 502, 503, 504:
+		return stt.ErrOverloaded
+	}
+	return stt.ErrUnknown
+}
+
+// convertErrorOpenAI2Coord wraps an error of the OpenAI client in the sentinel error of its
+// HTTP status code, keeping the message of the provider, and in an *llm.RetryAfterError if
+// the response header asks for a delay. Other errors are returned as is.
+func convertErrorOpenAI2Coord(err error, errorByStatus func(int) error, header http.Header) error {
+	var status int
+
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	}
+
+	if status == 0 {
+		return err
+	}
+
+	return retryafter.Wrap(fmt.Errorf("%w: %w", errorByStatus(status), err), header)
+}
+
+type responseHeaderKey struct{}
+
+// withResponseHeader returns a context whose failed request records its response header in
+// the returned header. The errors of the OpenAI client do not carry the response header, so
+// it is recorded by retryAfterDoer.
+func withResponseHeader(ctx context.Context) (context.Context, *http.Header) {
+	header := new(http.Header)
+	return context.WithValue(ctx, responseHeaderKey{}, header), header
+}
+
+// retryAfterDoer records the response header of failed requests for withResponseHeader.
+type retryAfterDoer struct {
+	next openai.HTTPDoer
+}
+
+func (d retryAfterDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.next.Do(req)
+	if err == nil && resp.StatusCode >= http.StatusBadRequest {
+		if header, ok := req.Context().Value(responseHeaderKey{}).(*http.Header); ok {
+			*header = resp.Header
+		}
+	}
+	return resp, err
+}
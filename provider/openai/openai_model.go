@@ -14,7 +14,6 @@ import (
 	"github.com/lemon-mint/coord/provider"
 
 	"github.com/sashabaranov/go-openai"
-	"github.com/sashabaranov/go-openai/jsonschema"
 )
 
 var _ llm.Model = (*openAIModel)(nil)
@@ -186,17 +185,15 @@ func convertContextCoord2OpenAI(ctx *llm.ChatContext, prompt ...*llm.Content) ([
 	var dst []openai.ChatCompletionMessage
 	var err error
 
+	var contents []*llm.Content
 	if ctx != nil {
-		for _, c := range ctx.Contents {
-			dst, err = convertContentCoord2OpenAI(dst, c)
-			if err != nil {
-				return dst, err
-			}
-		}
+		contents = append(contents, ctx.Contents...)
 	}
+	contents = append(contents, prompt...)
+	contents, _ = llmutils.PairFunctionResponses(contents, nil)
 
-	for _, p := range prompt {
-		dst, err = convertContentCoord2OpenAI(dst, p)
+	for _, c := range contents {
+		dst, err = convertContentCoord2OpenAI(dst, c)
 		if err != nil {
 			return dst, err
 		}
@@ -209,70 +206,49 @@ func convertFunctionCoord2OpenAI(f *llm.FunctionDeclaration) *openai.FunctionDef
 	return &openai.FunctionDefinition{
 		Name:        f.Name,
 		Description: f.Description,
-		Parameters:  convertSchemaCoord2OpenAI(f.Schema, nil),
+		Parameters:  convertSchemaCoord2OpenAI(f.Schema, false),
 	}
 }
 
-func convTypeCoord2OpenAI(t llm.OpenAPIType) jsonschema.DataType {
-	switch t {
-	case llm.OpenAPITypeString:
-		return jsonschema.String
-	case llm.OpenAPITypeNumber:
-		return jsonschema.Number
-	case llm.OpenAPITypeInteger:
-		return jsonschema.Integer
-	case llm.OpenAPITypeBoolean:
-		return jsonschema.Boolean
-	case llm.OpenAPITypeArray:
-		return jsonschema.Array
-	case llm.OpenAPITypeObject:
-		return jsonschema.Object
-	}
-
-	return jsonschema.Null
-}
-
-func convertSchemaCoord2OpenAI(s *llm.Schema, cache map[*llm.Schema]*jsonschema.Definition) *jsonschema.Definition {
-	if s == nil {
-		return nil
+func convertToolChoiceCoord2OpenAI(t *llm.ToolChoice) any {
+	if t.Name != "" {
+		return openai.ToolChoice{
+			Type:     openai.ToolTypeFunction,
+			Function: openai.ToolFunction{Name: t.Name},
+		}
 	}
 
-	if cache == nil {
-		cache = make(map[*llm.Schema]*jsonschema.Definition)
-	}
+	return string(t.Effective())
+}
 
-	if c, ok := cache[s]; ok {
-		return c
+func convertUsageOpenAI2Coord(u *openai.Usage) *llm.UsageData {
+	usage := &llm.UsageData{
+		InputTokens:  u.PromptTokens,
+		OutputTokens: u.CompletionTokens,
+		TotalTokens:  u.TotalTokens,
 	}
 
-	schema := &jsonschema.Definition{
-		Type:        convTypeCoord2OpenAI(s.Type),
-		Description: s.Description,
+	if d := u.PromptTokensDetails; d != nil {
+		usage.CacheReadTokens = d.CachedTokens
+		if d.AudioTokens > 0 {
+			usage.InputModalityTokens = map[llm.Modality]int{
+				llm.ModalityAudio: d.AudioTokens,
+				llm.ModalityText:  u.PromptTokens - d.AudioTokens,
+			}
+		}
 	}
-	cache[s] = schema
 
-	switch s.Type {
-	case llm.OpenAPITypeString:
-		schema.Enum = make([]string, 0, len(s.Enum))
-		for i := range s.Enum {
-			if v, ok := s.Enum[i].(string); ok {
-				schema.Enum = append(schema.Enum, v)
+	if d := u.CompletionTokensDetails; d != nil {
+		usage.ReasoningTokens = d.ReasoningTokens
+		if d.AudioTokens > 0 {
+			usage.OutputModalityTokens = map[llm.Modality]int{
+				llm.ModalityAudio: d.AudioTokens,
+				llm.ModalityText:  u.CompletionTokens - d.AudioTokens,
 			}
 		}
-	case llm.OpenAPITypeNumber:
-	case llm.OpenAPITypeInteger:
-	case llm.OpenAPITypeBoolean:
-	case llm.OpenAPITypeArray:
-		schema.Items = convertSchemaCoord2OpenAI(s.Items, cache)
-	case llm.OpenAPITypeObject:
-		schema.Properties = make(map[string]jsonschema.Definition, len(s.Properties))
-		for k, v := range s.Properties {
-			schema.Properties[k] = *convertSchemaCoord2OpenAI(v, cache)
-		}
-		schema.Required = s.Required
 	}
 
-	return schema
+	return usage
 }
 
 type streamingOpenAI2CoordConverter struct {
@@ -410,6 +386,14 @@ func (g *openAIModel) GenerateStream(ctx context.Context, chat *llm.ChatContext,
 		},
 	}
 
+	if chat != nil && chat.ToolChoice != nil && len(otools) > 0 {
+		model_request.ToolChoice = convertToolChoiceCoord2OpenAI(chat.ToolChoice)
+	}
+
+	if g.config.ParallelToolCalls != nil && len(otools) > 0 {
+		model_request.ParallelToolCalls = *g.config.ParallelToolCalls
+	}
+
 	if g.config.MaxOutputTokens == nil || *g.config.MaxOutputTokens <= 0 {
 		model_request.MaxTokens = 2048
 	} else {
@@ -424,11 +408,32 @@ func (g *openAIModel) GenerateStream(ctx context.Context, chat *llm.ChatContext,
 		model_request.TopP = *g.config.TopP
 	}
 
-	iter, err := g.client.CreateChatCompletionStream(ctx, model_request)
+	if rf := g.config.ResponseFormat; rf != nil {
+		if rf.Schema == nil {
+			model_request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+			}
+		} else {
+			// Schemas that strict mode can not express, such as maps, are only a hint to the model.
+			strict := strictSchemaOpenAI(rf.Schema)
+			model_request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+				JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+					Name:        rf.FormatName(),
+					Description: rf.Description,
+					Schema:      convertSchemaCoord2OpenAI(rf.Schema, strict),
+					Strict:      strict,
+				},
+			}
+		}
+	}
+
+	request_ctx, header := withResponseHeader(ctx)
+	iter, err := g.client.CreateChatCompletionStream(request_ctx, model_request)
 	if err != nil {
 		ch := make(chan llm.Segment)
 		close(ch)
-		v := &llm.StreamContent{Err: err, Content: &llm.Content{}, Stream: ch}
+		v := &llm.StreamContent{Err: convertErrorOpenAI2Coord(err, getErrorByStatus, *header), Content: &llm.Content{}, Stream: ch}
 		return v
 	}
 
@@ -456,18 +461,23 @@ func (g *openAIModel) GenerateStream(ctx context.Context, chat *llm.ChatContext,
 					return
 				}
 
-				v.Err = err
+				v.Err = convertErrorOpenAI2Coord(err, getErrorByStatus, nil)
 				return
 			}
 
+			if resp.ID != "" {
+				v.ResponseID = resp.ID
+			}
+			if resp.Model != "" {
+				v.Model = resp.Model
+			}
+
 			if resp.Usage != nil {
 				if v.UsageData == nil {
 					v.UsageData = new(llm.UsageData)
 				}
 
-				v.UsageData.InputTokens += resp.Usage.PromptTokens
-				v.UsageData.OutputTokens += resp.Usage.CompletionTokens
-				v.UsageData.TotalTokens += resp.Usage.TotalTokens
+				v.UsageData.Add(convertUsageOpenAI2Coord(resp.Usage))
 			}
 
 			if len(resp.Choices) > 0 {
@@ -2,9 +2,13 @@ package openai_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/lemon-mint/coord/llm"
 	"github.com/lemon-mint/coord/pconf"
@@ -207,3 +211,34 @@ func TestOpenAIToolCall(t *testing.T) {
 		return
 	}
 }
+
+func TestOpenAIRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests"}}`))
+	}))
+	defer server.Close()
+
+	client, err := openai.Provider.NewLLMClient(context.Background(), pconf.WithAPIKey("test"), pconf.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	model, err := client.NewLLM("gpt-4o-mini", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = model.GenerateStream(context.Background(), &llm.ChatContext{}, llm.TextContent(llm.RoleUser, "Hello!")).Wait()
+	if !errors.Is(err, llm.ErrRateLimit) {
+		t.Fatalf("err = %v, want %v", err, llm.ErrRateLimit)
+	}
+
+	var retryAfter *llm.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.Delay != 2*time.Second {
+		t.Errorf("expected a retry after 2s, got %v", err)
+	}
+}
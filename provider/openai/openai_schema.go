@@ -0,0 +1,185 @@
+package openai
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/lemon-mint/coord/internal/llmutils"
+	"github.com/lemon-mint/coord/llm"
+)
+
+// openAISchema is the JSON Schema subset accepted by the OpenAI API.
+type openAISchema struct {
+	Type        llm.OpenAPIType `json:"type,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Enum        []interface{}   `json:"enum,omitempty"`
+	Format      string          `json:"format,omitempty"`
+
+	Properties           map[string]*openAISchema `json:"properties,omitempty"`
+	Required             []string                 `json:"required,omitempty"`
+	AdditionalProperties interface{}              `json:"additionalProperties,omitempty"` // bool or *openAISchema
+	Items                *openAISchema            `json:"items,omitempty"`
+
+	AnyOf []*openAISchema          `json:"anyOf,omitempty"`
+	Ref   string                   `json:"$ref,omitempty"`
+	Defs  map[string]*openAISchema `json:"$defs,omitempty"`
+
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+}
+
+func (s *openAISchema) MarshalJSON() ([]byte, error) {
+	type alias openAISchema
+	return json.Marshal((*alias)(s))
+}
+
+// openAIUnsupportedKeywords are folded into the description of the schema.
+const openAIUnsupportedKeywords = llmutils.SchemaKeywordOneOf
+
+// openAIStrictUnsupportedKeywords are additionally folded into the description in strict mode.
+const openAIStrictUnsupportedKeywords = openAIUnsupportedKeywords | llmutils.SchemaKeywordMinLength | llmutils.SchemaKeywordMaxLength
+
+// convertSchemaCoord2OpenAI converts the schema. In strict mode, every object disallows
+// additional properties and requires all of its properties, and the optional properties
+// are made nullable instead, as required by structured outputs. The schema must then
+// satisfy strictSchemaOpenAI.
+func convertSchemaCoord2OpenAI(s *llm.Schema, strict bool) *openAISchema {
+	c := &schemaConverterOpenAI{
+		strict: strict,
+		cache:  make(map[*llm.Schema]*openAISchema),
+	}
+	return c.convert(s)
+}
+
+type schemaConverterOpenAI struct {
+	strict bool
+	cache  map[*llm.Schema]*openAISchema
+}
+
+func (c *schemaConverterOpenAI) convert(s *llm.Schema) *openAISchema {
+	if s == nil {
+		return nil
+	}
+
+	if v, ok := c.cache[s]; ok {
+		return v
+	}
+
+	keywords := llmutils.SchemaKeyword(openAIUnsupportedKeywords)
+	if c.strict {
+		keywords = openAIStrictUnsupportedKeywords
+	}
+
+	schema := &openAISchema{
+		Type:        s.Type,
+		Description: llmutils.DescribeSchema(s, keywords),
+		Enum:        s.Enum,
+		Format:      s.Format,
+		Ref:         s.Ref,
+		Minimum:     s.Minimum,
+		Maximum:     s.Maximum,
+		Pattern:     s.Pattern,
+		MinItems:    s.MinItems,
+		MaxItems:    s.MaxItems,
+	}
+	if !c.strict {
+		schema.MinLength = s.MinLength
+		schema.MaxLength = s.MaxLength
+	}
+	c.cache[s] = schema
+
+	switch s.Type {
+	case llm.OpenAPITypeArray:
+		schema.Items = c.convert(s.Items)
+	case llm.OpenAPITypeObject:
+		schema.Properties = make(map[string]*openAISchema, len(s.Properties))
+		for k, v := range s.Properties {
+			schema.Properties[k] = c.convert(v)
+		}
+		schema.Required = s.Required
+
+		if c.strict {
+			schema.AdditionalProperties = false
+			schema.Required = make([]string, 0, len(s.Properties))
+			for k, v := range s.Properties {
+				schema.Required = append(schema.Required, k)
+				if v.Nullable || !slices.Contains(s.Required, k) {
+					schema.Properties[k] = &openAISchema{AnyOf: []*openAISchema{schema.Properties[k], {Type: openAITypeNull}}}
+				}
+			}
+			slices.Sort(schema.Required)
+		} else if ap := s.AdditionalProperties; ap != nil {
+			if ap.Schema != nil {
+				schema.AdditionalProperties = c.convert(ap.Schema)
+			} else {
+				schema.AdditionalProperties = ap.Allowed
+			}
+		}
+	}
+
+	// OpenAI does not support oneOf, so it is relaxed to anyOf.
+	for _, v := range s.AnyOf {
+		schema.AnyOf = append(schema.AnyOf, c.convert(v))
+	}
+	for _, v := range s.OneOf {
+		schema.AnyOf = append(schema.AnyOf, c.convert(v))
+	}
+
+	if len(s.Defs) > 0 {
+		schema.Defs = make(map[string]*openAISchema, len(s.Defs))
+		for k, v := range s.Defs {
+			schema.Defs[k] = c.convert(v)
+		}
+	}
+
+	return schema
+}
+
+const openAITypeNull = llm.OpenAPIType("null")
+
+// strictSchemaOpenAI reports whether the schema can be used with strict structured outputs,
+// which require an object at the root and objects with a fixed set of properties.
+func strictSchemaOpenAI(s *llm.Schema) bool {
+	if s == nil || s.Type != llm.OpenAPITypeObject {
+		return false
+	}
+	return strictCompatibleOpenAI(s, make(map[*llm.Schema]bool))
+}
+
+func strictCompatibleOpenAI(s *llm.Schema, seen map[*llm.Schema]bool) bool {
+	if s == nil || seen[s] {
+		return true
+	}
+	seen[s] = true
+
+	if s.Type == llm.OpenAPITypeObject {
+		if len(s.Properties) == 0 {
+			return false
+		}
+		if ap := s.AdditionalProperties; ap != nil && (ap.Allowed || ap.Schema != nil) {
+			return false
+		}
+	}
+
+	children := []*llm.Schema{s.Items}
+	for _, v := range s.Properties {
+		children = append(children, v)
+	}
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+	for _, v := range s.Defs {
+		children = append(children, v)
+	}
+
+	for _, v := range children {
+		if !strictCompatibleOpenAI(v, seen) {
+			return false
+		}
+	}
+	return true
+}
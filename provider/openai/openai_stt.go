@@ -0,0 +1,136 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/provider"
+	"github.com/lemon-mint/coord/stt"
+	"github.com/lemon-mint/coord/tts"
+	"github.com/sashabaranov/go-openai"
+)
+
+type openAISTT struct {
+	client *openai.Client
+
+	model    string
+	language string
+	prompt   string
+
+	sample_rate int
+}
+
+var _ stt.Model = (*openAISTT)(nil)
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func (g *openAISTT) Transcribe(ctx context.Context, audio *tts.AudioFile) (*stt.Transcription, error) {
+	switch audio.Format {
+	case tts.FormatMP3, tts.FormatOGG, tts.FormatFLAC, tts.FormatWAV:
+	case tts.FormatLINEAR16, tts.FormatMULAW, tts.FormatALAW:
+		// Raw audio is uploaded as a WAV file.
+		if audio.SampleRate == 0 {
+			raw := *audio
+			raw.SampleRate = g.sample_rate
+			audio = &raw
+		}
+		wav, err := tts.Convert(audio, tts.FormatWAV, 0)
+		if err != nil {
+			return nil, err
+		}
+		audio = wav
+	default:
+		return nil, stt.ErrUnsupportedFileFormat
+	}
+
+	// Only whisper models report the detected language and timestamped segments.
+	verbose := strings.HasPrefix(g.model, "whisper")
+
+	model_request := openai.AudioRequest{
+		Model:    g.model,
+		FilePath: "audio" + audio.Format.Extension(),
+		Reader:   bytes.NewReader(audio.Data),
+		Prompt:   g.prompt,
+		Language: g.language,
+		Format:   openai.AudioResponseFormatJSON,
+	}
+
+	if verbose {
+		model_request.Format = openai.AudioResponseFormatVerboseJSON
+		model_request.TimestampGranularities = []openai.TranscriptionTimestampGranularity{
+			openai.TranscriptionTimestampGranularitySegment,
+		}
+	}
+
+	ctx, header := withResponseHeader(ctx)
+	resp, err := g.client.CreateTranscription(ctx, model_request)
+	if err != nil {
+		return nil, convertErrorOpenAI2Coord(err, getSTTErrorByStatus, *header)
+	}
+
+	result := &stt.Transcription{
+		Text:     strings.TrimSpace(resp.Text),
+		Language: resp.Language,
+	}
+
+	if len(resp.Segments) > 0 {
+		result.Segments = make([]stt.Segment, len(resp.Segments))
+		for i := range resp.Segments {
+			result.Segments[i] = stt.Segment{
+				Text:  strings.TrimSpace(resp.Segments[i].Text),
+				Start: secondsToDuration(resp.Segments[i].Start),
+				End:   secondsToDuration(resp.Segments[i].End),
+			}
+		}
+	}
+
+	return result, nil
+}
+
+var _ provider.STTClient = (*openAIClient)(nil)
+
+func (g *openAIClient) NewSTT(model string, config *stt.Config) (stt.Model, error) {
+	if config == nil {
+		config = &stt.Config{}
+	}
+
+	if model == "" {
+		model = openai.Whisper1
+	}
+
+	_sm := &openAISTT{
+		client:   g.client,
+		model:    model,
+		language: config.Language,
+		prompt:   config.Prompt,
+
+		sample_rate: config.SampleRate,
+	}
+
+	return _sm, nil
+}
+
+var _ provider.STTProvider = Provider
+
+func (OpenAIProvider) NewSTTClient(ctx context.Context, configs ...pconf.Config) (provider.STTClient, error) {
+	return newClient(configs...)
+}
+
+func init() {
+	var exists bool
+	for _, n := range coord.ListSTTProviders() {
+		if n == ProviderName {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		coord.RegisterSTTProvider(ProviderName, Provider)
+	}
+}
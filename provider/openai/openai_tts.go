@@ -5,6 +5,7 @@ import (
 	"io"
 
 	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/internal/ttsutils"
 	"github.com/lemon-mint/coord/pconf"
 	"github.com/lemon-mint/coord/provider"
 	"github.com/lemon-mint/coord/tts"
@@ -18,31 +19,51 @@ type openAITTS struct {
 	voice openai.SpeechVoice
 	speed float64
 
-	fmt tts.Format
+	fmt         tts.Format
+	sample_rate int
 }
 
 var _ tts.Model = (*openAITTS)(nil)
 
-func (g *openAITTS) GenerateSpeech(ctx context.Context, text string) (*tts.AudioFile, error) {
-	var encoding openai.SpeechResponseFormat
+// openAISampleRate is the sample rate of the pcm and wav outputs.
+const openAISampleRate = 24000
 
+// native returns the response format requested from the api and the matching tts.Format.
+// Formats without a native equivalent are converted from pcm.
+func (g *openAITTS) native() (openai.SpeechResponseFormat, tts.Format, error) {
 	switch g.fmt {
 	case tts.FormatMP3:
-		encoding = openai.SpeechResponseFormatMp3
+		return openai.SpeechResponseFormatMp3, g.fmt, nil
 	case tts.FormatOGG:
-		encoding = openai.SpeechResponseFormatOpus
+		return openai.SpeechResponseFormatOpus, g.fmt, nil
 	case tts.FormatAAC:
-		encoding = openai.SpeechResponseFormatAac
+		return openai.SpeechResponseFormatAac, g.fmt, nil
 	case tts.FormatFLAC:
-		encoding = openai.SpeechResponseFormatFlac
+		return openai.SpeechResponseFormatFlac, g.fmt, nil
 	case tts.FormatWAV:
-		encoding = openai.SpeechResponseFormatWav
-	case tts.FormatLINEAR16:
-		encoding = openai.SpeechResponseFormatPcm
-	default:
-		return nil, tts.ErrUnsupportedFileFormat
+		return openai.SpeechResponseFormatWav, g.fmt, nil
+	case tts.FormatLINEAR16, tts.FormatMULAW, tts.FormatALAW:
+		return openai.SpeechResponseFormatPcm, tts.FormatLINEAR16, nil
+	}
+
+	return "", "", tts.ErrUnsupportedFileFormat
+}
+
+// convert reports whether the native output has to be converted to the configured format.
+func (g *openAITTS) convert(native tts.Format) bool {
+	if native != g.fmt {
+		return true
 	}
 
+	switch native {
+	case tts.FormatLINEAR16, tts.FormatWAV:
+		return g.sample_rate != 0 && g.sample_rate != openAISampleRate
+	}
+	return false
+}
+
+func (g *openAITTS) createSpeech(ctx context.Context, text string, encoding openai.SpeechResponseFormat) (openai.RawResponse, error) {
+	ctx, header := withResponseHeader(ctx)
 	resp, err := g.client.CreateSpeech(ctx, openai.CreateSpeechRequest{
 		Model:          g.model,
 		Voice:          g.voice,
@@ -50,19 +71,65 @@ func (g *openAITTS) GenerateSpeech(ctx context.Context, text string) (*tts.Audio
 		ResponseFormat: encoding,
 		Input:          text,
 	})
+	if err != nil {
+		return resp, convertErrorOpenAI2Coord(err, getTTSErrorByStatus, *header)
+	}
+	return resp, nil
+}
+
+func (g *openAITTS) GenerateSpeech(ctx context.Context, text string) (*tts.AudioFile, error) {
+	encoding, native, err := g.native()
 	if err != nil {
 		return nil, err
 	}
 
+	resp, err := g.createSpeech(ctx, text, encoding)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+
 	file, err := io.ReadAll(resp)
 	if err != nil {
 		return nil, err
 	}
 
-	return &tts.AudioFile{
-		Format: g.fmt,
+	audio := &tts.AudioFile{
+		Format: native,
 		Data:   file,
-	}, nil
+	}
+
+	switch native {
+	case tts.FormatLINEAR16, tts.FormatWAV:
+		audio.SampleRate = openAISampleRate
+	}
+
+	if g.convert(native) {
+		return tts.Convert(audio, g.fmt, g.sample_rate)
+	}
+
+	return audio, nil
+}
+
+var _ tts.StreamModel = (*openAITTS)(nil)
+
+func (g *openAITTS) GenerateSpeechStream(ctx context.Context, text string) *tts.AudioStream {
+	encoding, native, err := g.native()
+	if err != nil {
+		return ttsutils.ErrorStream(err)
+	}
+
+	if g.convert(native) {
+		// Converted audio is delivered as a single chunk.
+		return tts.GenerateSpeechStream(ctx, struct{ tts.Model }{g}, text)
+	}
+
+	resp, err := g.createSpeech(ctx, text, encoding)
+	if err != nil {
+		return ttsutils.ErrorStream(err)
+	}
+
+	return ttsutils.ReaderStream(ctx, g.fmt, resp)
 }
 
 var defaultOpenAITTSConfig = &tts.Config{
@@ -80,9 +147,12 @@ func (g *openAIClient) NewTTS(model string, config *tts.Config) (tts.Model, erro
 
 	_em := &openAITTS{
 		client: g.client,
+		model:  openai.SpeechModel(model),
 		voice:  openai.SpeechVoice(config.Model),
 		speed:  config.SpeakingRate,
 		fmt:    config.Format,
+
+		sample_rate: config.SampleRate,
 	}
 
 	if _em.fmt == "" {
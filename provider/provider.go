@@ -6,6 +6,8 @@ import (
 	"github.com/lemon-mint/coord/embedding"
 	"github.com/lemon-mint/coord/llm"
 	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/rerank"
+	"github.com/lemon-mint/coord/stt"
 	"github.com/lemon-mint/coord/tts"
 )
 
@@ -35,3 +37,21 @@ type TTSClient interface {
 type TTSProvider interface {
 	NewTTSClient(ctx context.Context, configs ...pconf.Config) (TTSClient, error)
 }
+
+type STTClient interface {
+	NewSTT(model string, config *stt.Config) (stt.Model, error)
+	Close() error
+}
+
+type STTProvider interface {
+	NewSTTClient(ctx context.Context, configs ...pconf.Config) (STTClient, error)
+}
+
+type RerankClient interface {
+	NewRerank(model string, config *rerank.Config) (rerank.Model, error)
+	Close() error
+}
+
+type RerankProvider interface {
+	NewRerankClient(ctx context.Context, configs ...pconf.Config) (RerankClient, error)
+}
@@ -0,0 +1,238 @@
+package vertexai
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/provider"
+	"github.com/lemon-mint/coord/stt"
+	"github.com/lemon-mint/coord/tts"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/speech/v1"
+)
+
+func getSTTErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		return stt.ErrInvalidRequest
+	case 401:
+		return stt.ErrAuthentication
+	case 403:
+		return stt.ErrPermission
+	case 404:
+		return stt.ErrNotFound
+	case 429:
+		return stt.ErrRateLimit
+	case 500:
+		return stt.ErrInternalServer
+	case 503:
+		return stt.ErrOverloaded
+	}
+	return stt.ErrUnknown
+}
+
+// parseSpeechDuration parses a duration in the protobuf JSON form (e.g. "1.500s").
+func parseSpeechDuration(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	d, _ := time.ParseDuration(s)
+	return d
+}
+
+type speechToTextModel struct {
+	service *speech.Service
+
+	model    string
+	language string
+	prompt   string
+
+	sample_rate int64
+}
+
+var _ stt.Model = (*speechToTextModel)(nil)
+
+// Transcribe transcribes the audio with a synchronous recognize call,
+// which accepts up to one minute of audio.
+func (g *speechToTextModel) Transcribe(ctx context.Context, audio *tts.AudioFile) (*stt.Transcription, error) {
+	var encoding string
+	sample_rate := int64(audio.SampleRate)
+	if sample_rate == 0 {
+		sample_rate = g.sample_rate
+	}
+
+	switch audio.Format {
+	case tts.FormatLINEAR16:
+		encoding = "LINEAR16"
+	case tts.FormatFLAC:
+		encoding = "FLAC"
+	case tts.FormatOGG:
+		encoding = "OGG_OPUS"
+	case tts.FormatMULAW:
+		encoding = "MULAW"
+		if sample_rate == 0 {
+			sample_rate = 8000
+		}
+	case tts.FormatALAW:
+		encoding = "ALAW"
+		if sample_rate == 0 {
+			sample_rate = 8000
+		}
+	case tts.FormatWAV:
+		// The encoding and sample rate are read from the WAV header.
+	default:
+		return nil, stt.ErrUnsupportedFileFormat
+	}
+
+	if audio.Format == tts.FormatLINEAR16 && sample_rate == 0 {
+		return nil, fmt.Errorf("%w: sample rate is required for raw audio", stt.ErrInvalidRequest)
+	}
+
+	language := g.language
+	if language == "" {
+		language = defaultSpeechToTextLanguage
+	}
+
+	model_request := &speech.RecognizeRequest{
+		Audio: &speech.RecognitionAudio{
+			Content: base64.StdEncoding.EncodeToString(audio.Data),
+		},
+		Config: &speech.RecognitionConfig{
+			Encoding:                   encoding,
+			SampleRateHertz:            sample_rate,
+			LanguageCode:               language,
+			Model:                      g.model,
+			EnableAutomaticPunctuation: true,
+			EnableWordTimeOffsets:      true,
+		},
+	}
+
+	if g.prompt != "" {
+		model_request.Config.SpeechContexts = []*speech.SpeechContext{
+			{Phrases: []string{g.prompt}},
+		}
+	}
+
+	resp, err := g.service.Speech.Recognize(model_request).Context(ctx).Do()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) {
+			return nil, fmt.Errorf("%w: %w", getSTTErrorByStatus(apiErr.Code), err)
+		}
+		return nil, err
+	}
+
+	result := &stt.Transcription{}
+
+	var text strings.Builder
+	var offset time.Duration
+	for _, r := range resp.Results {
+		end := parseSpeechDuration(r.ResultEndTime)
+		if len(r.Alternatives) == 0 || r.Alternatives[0].Transcript == "" {
+			offset = end
+			continue
+		}
+		alt := r.Alternatives[0]
+
+		start := offset
+		if len(alt.Words) > 0 {
+			start = parseSpeechDuration(alt.Words[0].StartTime)
+		}
+		offset = end
+
+		segment := strings.TrimSpace(alt.Transcript)
+		if text.Len() > 0 {
+			text.WriteByte(' ')
+		}
+		text.WriteString(segment)
+
+		result.Segments = append(result.Segments, stt.Segment{
+			Text:  segment,
+			Start: start,
+			End:   end,
+		})
+
+		if result.Language == "" {
+			result.Language = r.LanguageCode
+		}
+	}
+	result.Text = text.String()
+
+	if result.Language == "" {
+		result.Language = language
+	}
+
+	return result, nil
+}
+
+const defaultSpeechToTextLanguage = "en-US"
+
+type speechToTextClient struct {
+	service *speech.Service
+}
+
+var _ provider.STTClient = (*speechToTextClient)(nil)
+
+func (g *speechToTextClient) NewSTT(model string, config *stt.Config) (stt.Model, error) {
+	if config == nil {
+		config = &stt.Config{}
+	}
+
+	_sm := &speechToTextModel{
+		service:     g.service,
+		model:       model,
+		language:    config.Language,
+		prompt:      config.Prompt,
+		sample_rate: int64(config.SampleRate),
+	}
+
+	return _sm, nil
+}
+
+func (g *speechToTextClient) Close() error {
+	return nil
+}
+
+var _ provider.STTProvider = (*VertexAIProvider)(nil)
+
+func (VertexAIProvider) NewSTTClient(ctx context.Context, configs ...pconf.Config) (provider.STTClient, error) {
+	client_config := pconf.GeneralConfig{}
+	for i := range configs {
+		configs[i].Apply(&client_config)
+	}
+
+	client_options := client_config.GoogleClientOptions
+	if client_config.GoogleCredentials != nil {
+		client_options = append(client_options, option.WithAuthCredentials(client_config.GoogleCredentials))
+	}
+
+	service, err := speech.NewService(ctx, client_options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &speechToTextClient{
+		service: service,
+	}, nil
+}
+
+func init() {
+	var exists bool
+	for _, n := range coord.ListSTTProviders() {
+		if n == ProviderName {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		coord.RegisterSTTProvider(ProviderName, Provider)
+	}
+}
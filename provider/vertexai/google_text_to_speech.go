@@ -2,6 +2,7 @@ package vertexai
 
 import (
 	"context"
+	"io"
 
 	texttospeech "cloud.google.com/go/texttospeech/apiv1"
 	"cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
@@ -31,7 +32,7 @@ func (g *textToSpeechModel) GenerateSpeech(ctx context.Context, text string) (*t
 	var encoding texttospeechpb.AudioEncoding
 
 	switch g.fmt {
-	case tts.FormatLINEAR16:
+	case tts.FormatLINEAR16, tts.FormatWAV:
 		encoding = texttospeechpb.AudioEncoding_LINEAR16
 	case tts.FormatMP3:
 		encoding = texttospeechpb.AudioEncoding_MP3
@@ -68,10 +69,115 @@ func (g *textToSpeechModel) GenerateSpeech(ctx context.Context, text string) (*t
 		return nil, err
 	}
 
-	return &tts.AudioFile{
+	audio := &tts.AudioFile{
 		Format: g.fmt,
 		Data:   resp.AudioContent,
-	}, nil
+	}
+
+	switch g.fmt {
+	case tts.FormatLINEAR16, tts.FormatWAV, tts.FormatMULAW, tts.FormatALAW:
+		// Uncompressed audio is returned with a WAV header.
+		header, samples, err := tts.ParseWAV(resp.AudioContent)
+		if err != nil {
+			break
+		}
+		audio.SampleRate = header.SampleRate
+		if g.fmt != tts.FormatWAV {
+			audio.Data = samples
+		}
+	}
+
+	return audio, nil
+}
+
+var _ tts.StreamModel = (*textToSpeechModel)(nil)
+
+// streamingSampleRate is the sample rate of the audio produced by the streaming synthesize API.
+const streamingSampleRate = 24000
+
+// GenerateSpeechStream streams the speech with the streaming synthesize API,
+// which only produces raw LINEAR16 audio at 24 kHz and is limited to Chirp 3 HD and Journey voices.
+// Other formats are synthesized with GenerateSpeech and delivered as a single chunk.
+func (g *textToSpeechModel) GenerateSpeechStream(ctx context.Context, text string) *tts.AudioStream {
+	if g.fmt != tts.FormatLINEAR16 || (g.sample_rate != 0 && g.sample_rate != streamingSampleRate) {
+		// Hide the StreamModel implementation to get the single chunk fallback.
+		return tts.GenerateSpeechStream(ctx, struct{ tts.Model }{g}, text)
+	}
+
+	stream := make(chan []byte, 8)
+	v := &tts.AudioStream{
+		Format: g.fmt,
+		Stream: stream,
+	}
+
+	go func() {
+		defer close(stream)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		sc, err := g.client.StreamingSynthesize(ctx)
+		if err != nil {
+			v.Err = err
+			return
+		}
+
+		err = sc.Send(&texttospeechpb.StreamingSynthesizeRequest{
+			StreamingRequest: &texttospeechpb.StreamingSynthesizeRequest_StreamingConfig{
+				StreamingConfig: &texttospeechpb.StreamingSynthesizeConfig{
+					Voice: &texttospeechpb.VoiceSelectionParams{
+						LanguageCode: g.language,
+						Name:         g.name,
+					},
+				},
+			},
+		})
+		if err != nil {
+			v.Err = err
+			return
+		}
+
+		err = sc.Send(&texttospeechpb.StreamingSynthesizeRequest{
+			StreamingRequest: &texttospeechpb.StreamingSynthesizeRequest_Input{
+				Input: &texttospeechpb.StreamingSynthesisInput{
+					InputSource: &texttospeechpb.StreamingSynthesisInput_Text{Text: text},
+				},
+			},
+		})
+		if err != nil {
+			v.Err = err
+			return
+		}
+
+		if err := sc.CloseSend(); err != nil {
+			v.Err = err
+			return
+		}
+
+		for {
+			resp, err := sc.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				v.Err = err
+				return
+			}
+
+			if len(resp.AudioContent) == 0 {
+				continue
+			}
+
+			select {
+			case stream <- resp.AudioContent:
+			case <-ctx.Done():
+				v.Err = ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return v
 }
 
 var defaultTextToSpeechConfig = &tts.Config{
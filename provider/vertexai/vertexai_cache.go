@@ -0,0 +1,167 @@
+package vertexai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lemon-mint/coord/llm"
+
+	"google.golang.org/genai"
+)
+
+const (
+	// cacheExpiryMargin is the minimum remaining lifetime of a cached content to be reused.
+	cacheExpiryMargin = time.Minute
+	// cacheRetryInterval is the time after a failed creation before the prefix is cached again.
+	// Creation fails for prompts below the minimum size of the model.
+	cacheRetryInterval = time.Minute
+)
+
+// cachePrefixGenerativeLanguage returns the number of history contents up to the last cache
+// breakpoint of the chat and the longest requested lifetime. ok is false if the chat has
+// no breakpoint. Gemini caches the system instruction and tools with the contents, so a
+// breakpoint on either caches them without contents.
+func cachePrefixGenerativeLanguage(chat *llm.ChatContext, history []*llm.Content) (n int, ttl time.Duration, ok bool) {
+	mark := func(c *llm.CacheControl) {
+		if c != nil {
+			ok = true
+			ttl = max(ttl, c.TTL)
+		}
+	}
+
+	mark(chat.SystemCacheControl)
+	for _, t := range chat.Tools {
+		mark(t.CacheControl)
+	}
+	for i, c := range history {
+		if c != nil && c.CacheControl != nil {
+			mark(c.CacheControl)
+			n = i + 1
+		}
+	}
+
+	return n, ttl, ok
+}
+
+// errCacheUnavailable is returned for a prefix whose cached content recently failed to be created.
+var errCacheUnavailable = errors.New("cached content unavailable")
+
+type generativeLanguageCacheEntry struct {
+	name   string // Name of the cached content (empty if the creation failed)
+	expire time.Time
+}
+
+// generativeLanguageCache reuses the cached contents created for identical prompt prefixes.
+type generativeLanguageCache struct {
+	mu       sync.Mutex
+	entries  map[[sha256.Size]byte]generativeLanguageCacheEntry
+	creating map[[sha256.Size]byte]chan struct{} // Closed when the creation of the prefix ends
+}
+
+// get returns the name of the cached content of the prefix, creating it if needed.
+// written is the number of tokens cached by the creation.
+func (c *generativeLanguageCache) get(ctx context.Context, client *genai.Client, model string, config *genai.GenerateContentConfig, contents []*genai.Content, ttl time.Duration) (name string, written int, err error) {
+	data, err := json.Marshal(struct {
+		Model             string
+		SystemInstruction *genai.Content
+		Tools             []*genai.Tool
+		ToolConfig        *genai.ToolConfig
+		Contents          []*genai.Content
+	}{model, config.SystemInstruction, config.Tools, config.ToolConfig, contents})
+	if err != nil {
+		return "", 0, err
+	}
+	key := sha256.Sum256(data)
+
+	// Concurrent requests with the same prefix wait for a single creation.
+	c.mu.Lock()
+	for {
+		now := time.Now()
+		e, ok := c.entries[key]
+		if ok && e.name == "" && now.Before(e.expire) {
+			c.mu.Unlock()
+			return "", 0, errCacheUnavailable
+		}
+		if ok && e.name != "" && e.expire.Sub(now) > cacheExpiryMargin {
+			c.mu.Unlock()
+			return e.name, 0, nil
+		}
+
+		wait, creating := c.creating[key]
+		if !creating {
+			break
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return "", 0, ctx.Err()
+		}
+		c.mu.Lock()
+	}
+
+	if c.creating == nil {
+		c.creating = make(map[[sha256.Size]byte]chan struct{})
+	}
+	done := make(chan struct{})
+	c.creating[key] = done
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.creating, key)
+		c.mu.Unlock()
+		close(done)
+	}()
+
+	now := time.Now()
+	cached, err := client.Caches.Create(ctx, model, &genai.CreateCachedContentConfig{
+		TTL:               ttl,
+		Contents:          contents,
+		SystemInstruction: config.SystemInstruction,
+		Tools:             config.Tools,
+		ToolConfig:        config.ToolConfig,
+	})
+	if err != nil {
+		// A canceled request says nothing about the prefix, so a waiting request creates it instead.
+		if ctx.Err() == nil {
+			c.put(key, generativeLanguageCacheEntry{expire: now.Add(cacheRetryInterval)})
+		}
+		return "", 0, err
+	}
+
+	e := generativeLanguageCacheEntry{name: cached.Name, expire: cached.ExpireTime}
+	if e.expire.IsZero() {
+		e.expire = now.Add(max(ttl, cacheExpiryMargin))
+	}
+	c.put(key, e)
+
+	if cached.UsageMetadata != nil {
+		written = int(cached.UsageMetadata.TotalTokenCount)
+	}
+
+	return cached.Name, written, nil
+}
+
+func (c *generativeLanguageCache) put(key [sha256.Size]byte, e generativeLanguageCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[[sha256.Size]byte]generativeLanguageCacheEntry)
+	}
+
+	now := time.Now()
+	for k, v := range c.entries {
+		if now.After(v.expire) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = e
+}
@@ -2,75 +2,290 @@ package vertexai
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/lemon-mint/coord"
 	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/internal/embedutils"
+	"github.com/lemon-mint/coord/llm"
 	"github.com/lemon-mint/coord/pconf"
 	"github.com/lemon-mint/coord/provider"
 	"google.golang.org/genai"
 )
 
 var _ embedding.Model = (*textEmbedding)(nil)
+var _ embedding.BatchModel = (*textEmbedding)(nil)
+var _ embedding.ResultModel = (*textEmbedding)(nil)
+var _ embedding.ContentModel = (*textEmbedding)(nil)
+
+// vertexAIMaxBatchSize returns the maximum number of instances accepted by a single predict call.
+func vertexAIMaxBatchSize(model string) int {
+	// gemini-embedding models only accept a single instance per request.
+	if strings.HasPrefix(model, "gemini-embedding") {
+		return 1
+	}
+	return 250
+}
 
 type textEmbedding struct {
 	client *genai.Client
 
-	model     string
-	outputDim int
+	model        string
+	outputDim    int
+	outputType   embedding.OutputType
+	normalize    bool
+	autoTruncate *bool
 }
 
-func (g *textEmbedding) TextEmbedding(ctx context.Context, text string, task embedding.TaskType) ([]float64, error) {
-	config := &genai.EmbedContentConfig{}
-
-	var err error
-
+func convertTaskTypeGenerativeLanguage(task embedding.TaskType) (string, error) {
 	switch task {
 	case embedding.TaskTypeGeneral:
-		config.TaskType = ""
+		return "", nil
 	case embedding.TaskTypeSearchQuery:
-		config.TaskType = "RETRIEVAL_QUERY"
+		return "RETRIEVAL_QUERY", nil
 	case embedding.TaskTypeSearchDocument:
-		config.TaskType = "RETRIEVAL_DOCUMENT"
+		return "RETRIEVAL_DOCUMENT", nil
 	case embedding.TaskTypeSemanticSimilarity:
-		config.TaskType = "SEMANTIC_SIMILARITY"
+		return "SEMANTIC_SIMILARITY", nil
 	case embedding.TaskTypeClassification:
-		config.TaskType = "CLASSIFICATION"
+		return "CLASSIFICATION", nil
 	case embedding.TaskTypeClustering:
-		config.TaskType = "CLUSTERING"
+		return "CLUSTERING", nil
 	case embedding.TaskTypeQA:
-		config.TaskType = "QUESTION_ANSWERING"
+		return "QUESTION_ANSWERING", nil
 	case embedding.TaskTypeFactVerification:
-		config.TaskType = "FACT_VERIFICATION"
-	default:
-		return nil, embedding.ErrUnsupported
+		return "FACT_VERIFICATION", nil
+	}
+
+	return "", embedding.ErrUnsupported
+}
+
+func (g *textEmbedding) embedContents(ctx context.Context, texts []string, task embedding.TaskType) (*embedding.Result, error) {
+	taskType, err := convertTaskTypeGenerativeLanguage(task)
+	if err != nil {
+		return nil, err
 	}
 
-	response, err := g.client.Models.EmbedContent(ctx, g.model, []*genai.Content{
-		&genai.Content{
+	// The genai SDK omits AutoTruncate when it is false, so inputs that must not be truncated
+	// are sent to the predict method directly.
+	if g.autoTruncate != nil && !*g.autoTruncate {
+		response, err := g.predictTextEmbeddings(ctx, texts, taskType)
+		if err != nil {
+			return nil, err
+		}
+		return g.convertEmbedContentResponse(response, len(texts))
+	}
+
+	config := &genai.EmbedContentConfig{
+		TaskType:     taskType,
+		AutoTruncate: g.autoTruncate != nil && *g.autoTruncate,
+	}
+
+	contents := make([]*genai.Content, len(texts))
+	for i := range texts {
+		contents[i] = &genai.Content{
 			Role: genai.RoleUser,
 			Parts: []*genai.Part{
-				&genai.Part{
-					Text: text,
+				{
+					Text: texts[i],
 				},
 			},
-		},
-	}, config)
+		}
+	}
+
+	response, err := g.client.Models.EmbedContent(ctx, g.model, contents, config)
+	if err != nil {
+		return nil, convertEmbeddingError(err)
+	}
+
+	return g.convertEmbedContentResponse(response, len(texts))
+}
+
+func (g *textEmbedding) convertEmbedContentResponse(response *genai.EmbedContentResponse, n int) (*embedding.Result, error) {
+	if len(response.Embeddings) != n {
+		return nil, embedding.ErrNoResult
+	}
+
+	result := &embedding.Result{
+		Embeddings: make([]*embedding.Embedding, len(response.Embeddings)),
+	}
+
+	for i := range response.Embeddings {
+		embeddings := response.Embeddings[i].Values
+
+		if g.outputDim > 0 && len(embeddings) > g.outputDim {
+			embeddings = embeddings[:g.outputDim]
+		}
+
+		values := make([]float64, len(embeddings))
+		for j := range embeddings {
+			values[j] = float64(embeddings[j])
+		}
+
+		e := &embedding.Embedding{
+			Values: embedutils.Postprocess(values, g.normalize, g.outputType),
+		}
+
+		if stats := response.Embeddings[i].Statistics; stats != nil {
+			e.InputTokens = int(stats.TokenCount)
+			e.Truncated = stats.Truncated
+
+			if result.UsageData == nil {
+				result.UsageData = new(embedding.UsageData)
+			}
+			result.UsageData.InputTokens += e.InputTokens
+		}
+
+		result.Embeddings[i] = e
+	}
+
+	if response.Metadata != nil {
+		if result.UsageData == nil {
+			result.UsageData = new(embedding.UsageData)
+		}
+		result.UsageData.BillableCharacters = int(response.Metadata.BillableCharacterCount)
+	}
+
+	return result, nil
+}
+
+type textEmbeddingInstance struct {
+	Content  string `json:"content"`
+	TaskType string `json:"task_type,omitempty"`
+}
+
+type textEmbeddingParameters struct {
+	AutoTruncate bool `json:"autoTruncate"`
+}
+
+type textEmbeddingRequest struct {
+	Instances  []textEmbeddingInstance `json:"instances"`
+	Parameters textEmbeddingParameters `json:"parameters"`
+}
+
+type textEmbeddingPrediction struct {
+	Embeddings struct {
+		Values     []float32 `json:"values"`
+		Statistics struct {
+			Truncated  bool    `json:"truncated"`
+			TokenCount float32 `json:"token_count"`
+		} `json:"statistics"`
+	} `json:"embeddings"`
+}
+
+type textEmbeddingResponse struct {
+	Predictions []textEmbeddingPrediction `json:"predictions"`
+	Metadata    *struct {
+		BillableCharacterCount int32 `json:"billableCharacterCount"`
+	} `json:"metadata"`
+}
+
+// predictTextEmbeddings embeds the texts with autoTruncate disabled, so that over-long inputs
+// fail instead of being truncated.
+func (g *textEmbedding) predictTextEmbeddings(ctx context.Context, texts []string, taskType string) (*genai.EmbedContentResponse, error) {
+	req := &textEmbeddingRequest{
+		Instances: make([]textEmbeddingInstance, len(texts)),
+	}
+	for i := range texts {
+		req.Instances[i] = textEmbeddingInstance{Content: texts[i], TaskType: taskType}
+	}
+
+	var resp textEmbeddingResponse
+	if err := vertexaiPredict(ctx, g.client, g.model, req, &resp, getEmbeddingErrorByStatus); err != nil {
+		if errors.Is(err, embedding.ErrInvalidRequest) && isMaxLengthMessage(err.Error()) {
+			return nil, fmt.Errorf("%w: %w", embedding.ErrMaxLengthExceeded, err)
+		}
+		return nil, err
+	}
+
+	response := &genai.EmbedContentResponse{
+		Embeddings: make([]*genai.ContentEmbedding, len(resp.Predictions)),
+	}
+	for i, p := range resp.Predictions {
+		response.Embeddings[i] = &genai.ContentEmbedding{
+			Values: p.Embeddings.Values,
+			Statistics: &genai.ContentEmbeddingStatistics{
+				Truncated:  p.Embeddings.Statistics.Truncated,
+				TokenCount: p.Embeddings.Statistics.TokenCount,
+			},
+		}
+	}
+	if resp.Metadata != nil {
+		response.Metadata = &genai.EmbedContentMetadata{BillableCharacterCount: resp.Metadata.BillableCharacterCount}
+	}
+
+	return response, nil
+}
+
+// convertEmbeddingError maps errors caused by over-long inputs to embedding.ErrMaxLengthExceeded
+// and other API errors to the sentinel error of their status code.
+func convertEmbeddingError(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && apiErr.Code == 400 && isMaxLengthMessage(apiErr.Message) {
+		return fmt.Errorf("%w: %w", embedding.ErrMaxLengthExceeded, err)
+	}
+
+	return convertErrorGenerativeLanguage(err, getEmbeddingErrorByStatus)
+}
+
+// isMaxLengthMessage reports whether an error message of the API is about an over-long input.
+func isMaxLengthMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "token") && (strings.Contains(msg, "exceed") || strings.Contains(msg, "limit"))
+}
+
+func (g *textEmbedding) TextEmbedding(ctx context.Context, text string, task embedding.TaskType) ([]float64, error) {
+	if isMultimodalEmbeddingModel(g.model) {
+		return g.multimodalEmbedding(ctx, []llm.Segment{llm.Text(text)})
+	}
+
+	result, err := g.embedContents(ctx, []string{text}, task)
 	if err != nil {
 		return nil, err
 	}
 
-	embeddings := response.Embeddings[0].Values
+	return result.Embeddings[0].Values, nil
+}
 
-	if g.outputDim > 0 && len(embeddings) > g.outputDim {
-		embeddings = embeddings[:g.outputDim]
+func (g *textEmbedding) BatchTextEmbedding(ctx context.Context, texts []string, task embedding.TaskType) ([][]float64, error) {
+	result, err := g.EmbedTexts(ctx, texts, task)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Values(), nil
+}
+
+func (g *textEmbedding) EmbedTexts(ctx context.Context, texts []string, task embedding.TaskType) (*embedding.Result, error) {
+	if isMultimodalEmbeddingModel(g.model) {
+		// The multimodal embedding model accepts a single instance per request.
+		return embedutils.BatchResult(texts, 1, func(texts []string) (*embedding.Result, error) {
+			values, err := g.multimodalEmbedding(ctx, []llm.Segment{llm.Text(texts[0])})
+			if err != nil {
+				return nil, err
+			}
+			return &embedding.Result{Embeddings: []*embedding.Embedding{{Values: values}}}, nil
+		})
 	}
 
-	var output []float64 = make([]float64, len(embeddings))
-	for i := range embeddings {
-		output[i] = float64(embeddings[i])
+	return embedutils.BatchResult(texts, vertexAIMaxBatchSize(g.model), func(texts []string) (*embedding.Result, error) {
+		return g.embedContents(ctx, texts, task)
+	})
+}
+
+func (g *textEmbedding) ContentEmbedding(ctx context.Context, parts []llm.Segment, task embedding.TaskType) ([]float64, error) {
+	if isMultimodalEmbeddingModel(g.model) {
+		return g.multimodalEmbedding(ctx, parts)
+	}
+
+	text, err := embedding.TextContent(parts)
+	if err != nil {
+		return nil, err
 	}
 
-	return output, nil
+	return g.TextEmbedding(ctx, text, task)
 }
 
 var _ provider.EmbeddingClient = (*vertexaiClient)(nil)
@@ -81,9 +296,12 @@ func (g *vertexaiClient) NewEmbedding(model string, config *embedding.Config) (e
 	}
 
 	_em := &textEmbedding{
-		client:    g.client,
-		model:     model,
-		outputDim: config.Dimension,
+		client:       g.client,
+		model:        model,
+		outputDim:    config.Dimension,
+		outputType:   config.OutputType,
+		normalize:    config.Normalize,
+		autoTruncate: config.AutoTruncate,
 	}
 
 	return _em, nil
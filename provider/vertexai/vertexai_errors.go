@@ -0,0 +1,84 @@
+package vertexai
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/llm"
+
+	"google.golang.org/genai"
+)
+
+func getErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		return llm.ErrInvalidRequest
+	case 401:
+		return llm.ErrAuthentication
+	case 403:
+		return llm.ErrPermission
+	case 404:
+		return llm.ErrNotFound
+	case 429:
+		return llm.ErrRateLimit
+	case 500:
+		return llm.ErrInternalServer
+	case 502, 503, 504:
+		return llm.ErrOverloaded
+	}
+	return llm.ErrUnknown
+}
+
+func getEmbeddingErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		return embedding.ErrInvalidRequest
+	case 401:
+		return embedding.ErrAuthentication
+	case 403:
+		return embedding.ErrPermission
+	case 404:
+		return embedding.ErrNotFound
+	case 429:
+		return embedding.ErrRateLimit
+	case 500:
+		return embedding.ErrInternalServer
+	case 502, 503, 504:
+		return embedding.ErrOverloaded
+	}
+	return embedding.ErrUnknown
+}
+
+// convertErrorGenerativeLanguage wraps an API error in the sentinel error of its status code,
+// keeping the message of the provider. The retry delay in the error details is reported with
+// an *llm.RetryAfterError. Other errors are returned as is.
+func convertErrorGenerativeLanguage(err error, errorByStatus func(int) error) error {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code == 0 {
+		return err
+	}
+
+	err = fmt.Errorf("%w: %w", errorByStatus(apiErr.Code), err)
+	if d := retryDelayGenerativeLanguage(apiErr.Details); d > 0 {
+		return &llm.RetryAfterError{Err: err, Delay: d}
+	}
+	return err
+}
+
+// retryDelayGenerativeLanguage returns the delay of the google.rpc.RetryInfo error detail.
+func retryDelayGenerativeLanguage(details []map[string]any) time.Duration {
+	for _, d := range details {
+		if t, _ := d["@type"].(string); t != "type.googleapis.com/google.rpc.RetryInfo" {
+			continue
+		}
+
+		if s, ok := d["retryDelay"].(string); ok {
+			if delay, err := time.ParseDuration(s); err == nil && delay > 0 {
+				return delay
+			}
+		}
+	}
+	return 0
+}
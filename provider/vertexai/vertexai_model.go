@@ -37,17 +37,45 @@ func convTypeGenerativeLanguage(t llm.OpenAPIType) genai.Type {
 	return genai.TypeUnspecified
 }
 
-func convertSchemaGenerativeLanguage(s *llm.Schema, cache map[*llm.Schema]*genai.Schema) *genai.Schema {
+// generativeLanguageUnsupportedKeywords are folded into the description of the schema.
+const generativeLanguageUnsupportedKeywords = llmutils.SchemaKeywordOneOf | llmutils.SchemaKeywordAdditionalProperties
+
+func convertSchemaGenerativeLanguage(s *llm.Schema) *genai.Schema {
+	if s == nil {
+		return nil
+	}
+
+	c := &schemaConverterGenerativeLanguage{
+		root:     s,
+		cache:    make(map[*llm.Schema]*genai.Schema),
+		building: make(map[*llm.Schema]bool),
+	}
+	return c.convert(s)
+}
+
+// schemaConverterGenerativeLanguage converts a llm.Schema and the schemas it references.
+// Gemini does not support references, so they are inlined. A reference to a schema that
+// is being converted can not be inlined and is replaced by an object.
+type schemaConverterGenerativeLanguage struct {
+	root     *llm.Schema
+	cache    map[*llm.Schema]*genai.Schema
+	building map[*llm.Schema]bool
+}
+
+func (c *schemaConverterGenerativeLanguage) convert(s *llm.Schema) *genai.Schema {
 	if s == nil {
 		return nil
 	}
 
-	if cache == nil {
-		cache = make(map[*llm.Schema]*genai.Schema)
+	if v, ok := c.cache[s]; ok {
+		return v
 	}
 
-	if c, ok := cache[s]; ok {
-		return c
+	if c.building[s] {
+		return &genai.Schema{
+			Type:        genai.TypeObject,
+			Description: "Recursive reference.",
+		}
 	}
 
 	nullable := (*bool)(nil)
@@ -55,13 +83,50 @@ func convertSchemaGenerativeLanguage(s *llm.Schema, cache map[*llm.Schema]*genai
 		nullable = ptrify(true)
 	}
 
+	if s.Ref != "" {
+		ref := c.root.Resolve(s.Ref)
+		if ref == nil {
+			return &genai.Schema{Description: s.Description, Nullable: nullable}
+		}
+
+		if c.building[ref] {
+			return &genai.Schema{
+				Type:        genai.TypeObject,
+				Description: "Recursive reference to " + s.Ref + ".",
+				Nullable:    nullable,
+			}
+		}
+
+		schema := *c.convert(ref)
+		if s.Description != "" {
+			schema.Description = s.Description
+		}
+		if nullable != nil {
+			schema.Nullable = nullable
+		}
+		return &schema
+	}
+
+	c.building[s] = true
+	defer delete(c.building, s)
+
 	schema := &genai.Schema{
 		Type:        convTypeGenerativeLanguage(s.Type),
-		Description: s.Description,
+		Description: llmutils.DescribeSchema(s, generativeLanguageUnsupportedKeywords),
 		Nullable:    nullable,
 		Format:      s.Format,
+		Pattern:     s.Pattern,
+		Minimum:     s.Minimum,
+		Maximum:     s.Maximum,
+		MinLength:   int64ptr(s.MinLength),
+		MaxLength:   int64ptr(s.MaxLength),
+		MinItems:    int64ptr(s.MinItems),
+		MaxItems:    int64ptr(s.MaxItems),
+	}
+	if s.Type == "" {
+		// A schema without a type (e.g. a union) omits it.
+		schema.Type = ""
 	}
-	cache[s] = schema
 
 	switch s.Type {
 	case llm.OpenAPITypeString:
@@ -75,28 +140,62 @@ func convertSchemaGenerativeLanguage(s *llm.Schema, cache map[*llm.Schema]*genai
 	case llm.OpenAPITypeInteger:
 	case llm.OpenAPITypeBoolean:
 	case llm.OpenAPITypeArray:
-		schema.Items = convertSchemaGenerativeLanguage(s.Items, cache)
+		schema.Items = c.convert(s.Items)
 	case llm.OpenAPITypeObject:
 		schema.Properties = make(map[string]*genai.Schema, len(s.Properties))
 		for k, v := range s.Properties {
-			schema.Properties[k] = convertSchemaGenerativeLanguage(v, cache)
+			schema.Properties[k] = c.convert(v)
 		}
 		schema.Required = s.Required
 	}
 
+	// Gemini does not support oneOf, so it is relaxed to anyOf.
+	for _, v := range s.AnyOf {
+		schema.AnyOf = append(schema.AnyOf, c.convert(v))
+	}
+	for _, v := range s.OneOf {
+		schema.AnyOf = append(schema.AnyOf, c.convert(v))
+	}
+
+	c.cache[s] = schema
 	return schema
 }
 
+func int64ptr(v *int) *int64 {
+	if v == nil {
+		return nil
+	}
+	return ptrify(int64(*v))
+}
+
 func convertFunctionDeclarationGenerativeLanguage(f *llm.FunctionDeclaration) *genai.FunctionDeclaration {
 	decl := genai.FunctionDeclaration{
 		Name:        f.Name,
 		Description: f.Description,
-		Parameters:  convertSchemaGenerativeLanguage(f.Schema, nil),
+		Parameters:  convertSchemaGenerativeLanguage(f.Schema),
 	}
 
 	return &decl
 }
 
+func convertToolChoiceGenerativeLanguage(t *llm.ToolChoice) *genai.FunctionCallingConfig {
+	if t.Name != "" {
+		return &genai.FunctionCallingConfig{
+			Mode:                 genai.FunctionCallingConfigModeAny,
+			AllowedFunctionNames: []string{t.Name},
+		}
+	}
+
+	switch t.Effective() {
+	case llm.ToolChoiceNone:
+		return &genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeNone}
+	case llm.ToolChoiceRequired:
+		return &genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeAny}
+	}
+
+	return &genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeAuto}
+}
+
 func convertContentGenerativeLanguage(s *llm.Content) *genai.Content {
 	content := &genai.Content{
 		Role: string(s.Role),
@@ -123,6 +222,7 @@ func convertContentGenerativeLanguage(s *llm.Content) *genai.Content {
 		case *llm.FunctionCall:
 			content.Parts = append(content.Parts, &genai.Part{
 				FunctionCall: &genai.FunctionCall{
+					ID:   p.ID,
 					Name: p.Name,
 					Args: p.Args,
 				},
@@ -142,6 +242,7 @@ func convertContentGenerativeLanguage(s *llm.Content) *genai.Content {
 
 			content.Parts = append(content.Parts, &genai.Part{
 				FunctionResponse: &genai.FunctionResponse{
+					ID:       p.ID,
 					Name:     p.Name,
 					Response: data,
 				},
@@ -152,11 +253,11 @@ func convertContentGenerativeLanguage(s *llm.Content) *genai.Content {
 	return content
 }
 
-func convertContextGenerativeLanguage(c *llm.ChatContext) []*genai.Content {
-	contents := make([]*genai.Content, len(c.Contents))
+func convertContextGenerativeLanguage(c []*llm.Content) []*genai.Content {
+	contents := make([]*genai.Content, len(c))
 
-	for i := range c.Contents {
-		contents[i] = convertContentGenerativeLanguage(c.Contents[i])
+	for i := range c {
+		contents[i] = convertContentGenerativeLanguage(c[i])
 	}
 
 	return contents
@@ -177,15 +278,24 @@ func convertGenerativeLanguageContent(c *genai.Content) *llm.Content {
 				Data:     c.Parts[i].InlineData.Data,
 			})
 		} else if c.Parts[i].FunctionCall != nil {
+			id := c.Parts[i].FunctionCall.ID
+			if id == "" {
+				// Gemini does not always return call IDs, so one is synthesized for pairing the response.
+				id = callid.OpenAICallID()
+			}
 			lc.Parts = append(lc.Parts, &llm.FunctionCall{
 				Name: c.Parts[i].FunctionCall.Name,
-				ID:   callid.OpenAICallID(),
+				ID:   id,
 				Args: c.Parts[i].FunctionCall.Args,
 			})
 		} else if c.Parts[i].FunctionResponse != nil {
+			id := c.Parts[i].FunctionResponse.ID
+			if id == "" {
+				id = callid.OpenAICallID()
+			}
 			lc.Parts = append(lc.Parts, &llm.FunctionResponse{
 				Name:    c.Parts[i].FunctionResponse.Name,
-				ID:      callid.OpenAICallID(),
+				ID:      id,
 				Content: c.Parts[i].FunctionResponse.Response,
 			})
 		}
@@ -194,6 +304,48 @@ func convertGenerativeLanguageContent(c *genai.Content) *llm.Content {
 	return lc
 }
 
+func convertGenerativeLanguageUsage(u *genai.GenerateContentResponseUsageMetadata) *llm.UsageData {
+	// Gemini reports thoughts separately from the candidates.
+	output_tokens := int(u.CandidatesTokenCount + u.ThoughtsTokenCount)
+
+	return &llm.UsageData{
+		InputTokens:          int(u.PromptTokenCount),
+		OutputTokens:         output_tokens,
+		TotalTokens:          int(u.TotalTokenCount),
+		CacheReadTokens:      int(u.CachedContentTokenCount),
+		ReasoningTokens:      int(u.ThoughtsTokenCount),
+		InputModalityTokens:  convertGenerativeLanguageModalityTokens(u.PromptTokensDetails),
+		OutputModalityTokens: convertGenerativeLanguageModalityTokens(u.CandidatesTokensDetails),
+	}
+}
+
+func convertGenerativeLanguageModalityTokens(details []*genai.ModalityTokenCount) map[llm.Modality]int {
+	if len(details) == 0 {
+		return nil
+	}
+
+	tokens := make(map[llm.Modality]int, len(details))
+	for _, d := range details {
+		var modality llm.Modality
+		switch d.Modality {
+		case genai.MediaModalityText:
+			modality = llm.ModalityText
+		case genai.MediaModalityImage:
+			modality = llm.ModalityImage
+		case genai.MediaModalityAudio:
+			modality = llm.ModalityAudio
+		case genai.MediaModalityVideo:
+			modality = llm.ModalityVideo
+		case genai.MediaModalityDocument:
+			modality = llm.ModalityDocument
+		default:
+			continue
+		}
+		tokens[modality] += int(d.TokenCount)
+	}
+	return tokens
+}
+
 func convertGenerativeLanguageFinishReason(stop_reason genai.FinishReason) llm.FinishReason {
 	switch stop_reason {
 	case genai.FinishReasonStop:
@@ -216,7 +368,9 @@ func (g *generativeLanguageModel) GenerateStream(ctx context.Context, chat *llm.
 		chat = &llm.ChatContext{}
 	}
 
-	contents := convertContextGenerativeLanguage(chat)
+	// Gemini matches function responses to calls by name and order, not by ID.
+	history, input := llmutils.PairFunctionResponses(chat.Contents, input)
+	contents := convertContextGenerativeLanguage(history)
 	tools := make([]*genai.FunctionDeclaration, len(chat.Tools))
 	for i := range chat.Tools {
 		tools[i] = convertFunctionDeclarationGenerativeLanguage(chat.Tools[i])
@@ -283,6 +437,11 @@ func (g *generativeLanguageModel) GenerateStream(ctx context.Context, chat *llm.
 		}
 	}
 
+	if g.config.ResponseFormat != nil {
+		config.ResponseMIMEType = "application/json"
+		config.ResponseSchema = convertSchemaGenerativeLanguage(g.config.ResponseFormat.Schema)
+	}
+
 	stream := make(chan llm.Segment, 128)
 	v := &llm.StreamContent{
 		Content: &llm.Content{},
@@ -295,12 +454,32 @@ func (g *generativeLanguageModel) GenerateStream(ctx context.Context, chat *llm.
 				FunctionDeclarations: tools,
 			},
 		}
+
+		if chat.ToolChoice != nil {
+			config.ToolConfig = &genai.ToolConfig{
+				FunctionCallingConfig: convertToolChoiceGenerativeLanguage(chat.ToolChoice),
+			}
+		}
+	}
+
+	var cache_write_tokens int
+	if n, ttl, ok := cachePrefixGenerativeLanguage(chat, history); ok {
+		// Caching is best effort: if the cached content can not be created, the prompt is sent uncached.
+		name, written, err := g.cache.get(ctx, g.client, model, config, contents[:n], ttl)
+		if err == nil {
+			config.CachedContent = name
+			config.SystemInstruction = nil
+			config.Tools = nil
+			config.ToolConfig = nil
+			contents = contents[n:]
+			cache_write_tokens = written
+		}
 	}
 
 	session, err := g.client.Chats.Create(ctx, model, config, contents)
 	if err != nil {
 		close(stream)
-		v.Err = err
+		v.Err = convertErrorGenerativeLanguage(err, getErrorByStatus)
 		return v
 	}
 
@@ -326,16 +505,20 @@ func (g *generativeLanguageModel) GenerateStream(ctx context.Context, chat *llm.
 				return
 			}
 			if err != nil {
-				v.Err = err
+				v.Err = convertErrorGenerativeLanguage(err, getErrorByStatus)
 				return
 			}
 
+			if resp.ResponseID != "" {
+				v.ResponseID = resp.ResponseID
+			}
+			if resp.ModelVersion != "" {
+				v.Model = resp.ModelVersion
+			}
+
 			if resp.UsageMetadata != nil {
-				v.UsageData = &llm.UsageData{
-					InputTokens:  int(resp.UsageMetadata.PromptTokenCount),
-					OutputTokens: int(resp.UsageMetadata.CandidatesTokenCount),
-					TotalTokens:  int(resp.UsageMetadata.TotalTokenCount),
-				}
+				v.UsageData = convertGenerativeLanguageUsage(resp.UsageMetadata)
+				v.UsageData.CacheWriteTokens = cache_write_tokens
 			}
 
 			if len(resp.Candidates) > 0 {
@@ -421,6 +604,8 @@ type generativeLanguageModel struct {
 	client *genai.Client
 	config *llm.Config
 	model  string
+
+	cache generativeLanguageCache
 }
 
 var _ provider.LLMClient = (*vertexaiClient)(nil)
@@ -0,0 +1,108 @@
+package vertexai
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/internal/embedutils"
+	"github.com/lemon-mint/coord/llm"
+)
+
+type multimodalEmbeddingImage struct {
+	BytesBase64Encoded []byte `json:"bytesBase64Encoded,omitempty"`
+	GCSURI             string `json:"gcsUri,omitempty"`
+	MIMEType           string `json:"mimeType,omitempty"`
+}
+
+type multimodalEmbeddingInstance struct {
+	Text  string                    `json:"text,omitempty"`
+	Image *multimodalEmbeddingImage `json:"image,omitempty"`
+}
+
+type multimodalEmbeddingParameters struct {
+	Dimension int `json:"dimension,omitempty"`
+}
+
+type multimodalEmbeddingRequest struct {
+	Instances  []multimodalEmbeddingInstance  `json:"instances"`
+	Parameters *multimodalEmbeddingParameters `json:"parameters,omitempty"`
+}
+
+type multimodalEmbeddingPrediction struct {
+	TextEmbedding  []float64 `json:"textEmbedding,omitempty"`
+	ImageEmbedding []float64 `json:"imageEmbedding,omitempty"`
+}
+
+type multimodalEmbeddingResponse struct {
+	Predictions []multimodalEmbeddingPrediction `json:"predictions"`
+}
+
+func isMultimodalEmbeddingModel(model string) bool {
+	return strings.HasPrefix(model, "multimodalembedding")
+}
+
+// multimodalEmbedding embeds either text or a single image with a multimodalembedding model.
+// Text and images are embedded into the same space, but the API returns a separate vector for each,
+// so content mixing text and images is not supported.
+func (g *textEmbedding) multimodalEmbedding(ctx context.Context, parts []llm.Segment) ([]float64, error) {
+	var instance multimodalEmbeddingInstance
+	var text strings.Builder
+
+	for i := range parts {
+		switch p := parts[i].(type) {
+		case llm.Text:
+			text.WriteString(string(p))
+		case *llm.InlineData:
+			if instance.Image != nil || !strings.HasPrefix(p.MIMEType, "image/") {
+				return nil, embedding.ErrUnsupported
+			}
+			instance.Image = &multimodalEmbeddingImage{
+				BytesBase64Encoded: p.Data,
+				MIMEType:           p.MIMEType,
+			}
+		case *llm.FileData:
+			if instance.Image != nil || !strings.HasPrefix(p.MIMEType, "image/") {
+				return nil, embedding.ErrUnsupported
+			}
+			instance.Image = &multimodalEmbeddingImage{
+				GCSURI:   p.FileURI,
+				MIMEType: p.MIMEType,
+			}
+		default:
+			return nil, embedding.ErrUnsupported
+		}
+	}
+
+	if instance.Image != nil && text.Len() > 0 {
+		return nil, embedding.ErrUnsupported
+	}
+	instance.Text = text.String()
+
+	req := &multimodalEmbeddingRequest{
+		Instances: []multimodalEmbeddingInstance{instance},
+	}
+	if g.outputDim > 0 {
+		req.Parameters = &multimodalEmbeddingParameters{Dimension: g.outputDim}
+	}
+
+	var resp multimodalEmbeddingResponse
+	if err := vertexaiPredict(ctx, g.client, g.model, req, &resp, getEmbeddingErrorByStatus); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Predictions) == 0 {
+		return nil, embedding.ErrNoResult
+	}
+
+	output := resp.Predictions[0].TextEmbedding
+	if instance.Image != nil {
+		output = resp.Predictions[0].ImageEmbedding
+	}
+
+	if len(output) == 0 {
+		return nil, embedding.ErrNoResult
+	}
+
+	return embedutils.Postprocess(output, g.normalize, g.outputType), nil
+}
@@ -0,0 +1,209 @@
+package vertexai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/provider"
+	"github.com/lemon-mint/coord/rerank"
+
+	"google.golang.org/api/discoveryengine/v1"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// rankingMaxRecords is the maximum number of records accepted by a single rank call.
+const rankingMaxRecords = 200
+
+func getRerankErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		return rerank.ErrInvalidRequest
+	case 401:
+		return rerank.ErrAuthentication
+	case 403:
+		return rerank.ErrPermission
+	case 404:
+		return rerank.ErrNotFound
+	case 429:
+		return rerank.ErrRateLimit
+	case 500:
+		return rerank.ErrInternalServer
+	case 503:
+		return rerank.ErrOverloaded
+	}
+	return rerank.ErrUnknown
+}
+
+var _ rerank.Model = (*rankingModel)(nil)
+
+type rankingModel struct {
+	service       *discoveryengine.Service
+	rankingConfig string
+
+	model string
+	topN  int
+}
+
+func (g *rankingModel) rank(ctx context.Context, query string, documents []string, offset int) ([]rerank.Result, error) {
+	records := make([]*discoveryengine.GoogleCloudDiscoveryengineV1RankingRecord, len(documents))
+	for i := range documents {
+		records[i] = &discoveryengine.GoogleCloudDiscoveryengineV1RankingRecord{
+			Id:      strconv.Itoa(offset + i),
+			Content: documents[i],
+		}
+	}
+
+	response, err := g.service.Projects.Locations.RankingConfigs.Rank(g.rankingConfig, &discoveryengine.GoogleCloudDiscoveryengineV1RankRequest{
+		Model:                         g.model,
+		Query:                         query,
+		Records:                       records,
+		TopN:                          int64(g.topN),
+		IgnoreRecordDetailsInResponse: true,
+	}).Context(ctx).Do()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) {
+			return nil, fmt.Errorf("%w: %w", getRerankErrorByStatus(apiErr.Code), err)
+		}
+		return nil, err
+	}
+
+	results := make([]rerank.Result, len(response.Records))
+	for i := range response.Records {
+		index, err := strconv.Atoi(response.Records[i].Id)
+		if err != nil || index < offset || index >= offset+len(documents) {
+			return nil, rerank.ErrInvalidResponse
+		}
+
+		results[i] = rerank.Result{
+			Index: index,
+			Score: response.Records[i].Score,
+		}
+	}
+
+	return results, nil
+}
+
+func (g *rankingModel) Rerank(ctx context.Context, query string, documents []string) ([]rerank.Result, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	var results []rerank.Result
+	for offset := 0; offset < len(documents); offset += rankingMaxRecords {
+		end := offset + rankingMaxRecords
+		if end > len(documents) {
+			end = len(documents)
+		}
+
+		chunk, err := g.rank(ctx, query, documents[offset:end], offset)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunk...)
+	}
+
+	if len(results) == 0 {
+		return nil, rerank.ErrNoResult
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if g.topN > 0 && len(results) > g.topN {
+		results = results[:g.topN]
+	}
+
+	return results, nil
+}
+
+type rankingClient struct {
+	service       *discoveryengine.Service
+	rankingConfig string
+}
+
+var _ provider.RerankClient = (*rankingClient)(nil)
+
+func (g *rankingClient) NewRerank(model string, config *rerank.Config) (rerank.Model, error) {
+	if config == nil {
+		config = &rerank.Config{}
+	}
+
+	_rm := &rankingModel{
+		service:       g.service,
+		rankingConfig: g.rankingConfig,
+		model:         model,
+		topN:          config.TopN,
+	}
+
+	return _rm, nil
+}
+
+func (g *rankingClient) Close() error {
+	return nil
+}
+
+var _ provider.RerankProvider = (*VertexAIProvider)(nil)
+
+func (VertexAIProvider) NewRerankClient(ctx context.Context, configs ...pconf.Config) (provider.RerankClient, error) {
+	client_config := pconf.GeneralConfig{}
+	for i := range configs {
+		configs[i].Apply(&client_config)
+	}
+
+	projectID := client_config.ProjectID
+	if projectID == "" {
+		return nil, ErrProjectIDNotSet
+	}
+
+	// The ranking API is only served from multi-region locations.
+	location := client_config.Location
+	switch location {
+	case "global", "us", "eu":
+	default:
+		location = "global"
+	}
+
+	client_options := client_config.GoogleClientOptions
+	if client_config.GoogleCredentials != nil {
+		client_options = append(client_options, option.WithAuthCredentials(client_config.GoogleCredentials))
+	}
+
+	// Multi-region locations other than global are served from their own endpoint.
+	switch {
+	case client_config.BaseURL != "":
+		client_options = append(client_options, option.WithEndpoint(client_config.BaseURL))
+	case location != "global":
+		client_options = append(client_options, option.WithEndpoint(fmt.Sprintf("https://%s-discoveryengine.googleapis.com/", location)))
+	}
+
+	service, err := discoveryengine.NewService(ctx, client_options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rankingClient{
+		service:       service,
+		rankingConfig: fmt.Sprintf("projects/%s/locations/%s/rankingConfigs/default_ranking_config", projectID, location),
+	}, nil
+}
+
+func init() {
+	var exists bool
+	for _, n := range coord.ListRerankProviders() {
+		if n == ProviderName {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		coord.RegisterRerankProvider(ProviderName, Provider)
+	}
+}
@@ -0,0 +1,64 @@
+package vertexai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/lemon-mint/coord/internal/retryafter"
+
+	"google.golang.org/genai"
+)
+
+// vertexaiPredict calls the predict method of a publisher model that is not covered by the genai SDK.
+// errorByStatus maps non-200 HTTP status codes to the sentinel errors of the calling package.
+func vertexaiPredict(ctx context.Context, client *genai.Client, model string, req interface{}, resp interface{}, errorByStatus func(int) error) error {
+	cc := client.ClientConfig()
+
+	endpoint, err := url.JoinPath(cc.HTTPOptions.BaseURL, "v1", "projects", cc.Project, "locations", cc.Location, "publishers", "google", "models", model+":predict")
+	if err != nil {
+		return err
+	}
+
+	return vertexaiPost(ctx, cc.HTTPClient, endpoint, req, resp, errorByStatus)
+}
+
+type vertexaiErrorBody struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func vertexaiPost(ctx context.Context, httpClient *http.Client, endpoint string, req interface{}, resp interface{}, errorByStatus func(int) error) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	hresp, err := httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+
+	if hresp.StatusCode != http.StatusOK {
+		err := errorByStatus(hresp.StatusCode)
+		var body vertexaiErrorBody
+		if json.NewDecoder(io.LimitReader(hresp.Body, 64<<10)).Decode(&body) == nil && body.Error.Message != "" {
+			err = fmt.Errorf("%w: %s", err, body.Error.Message)
+		}
+		return retryafter.Wrap(err, hresp.Header)
+	}
+
+	return json.NewDecoder(hresp.Body).Decode(resp)
+}
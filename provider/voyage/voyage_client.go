@@ -1,32 +1,103 @@
 package voyage
 
-type voyageInputType string
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
-const (
-	voyageInputNone     voyageInputType = ""
-	voyageInputQuery    voyageInputType = "query"
-	voyageInputDocument voyageInputType = "document"
+	"github.com/lemon-mint/coord/internal/retryafter"
+	"github.com/lemon-mint/coord/internal/useragent"
 )
 
-type voyageEmbeddingRequest struct {
-	Input     []string        `json:"input"`
-	Model     string          `json:"model"`
-	InputType voyageInputType `json:"input_type"`
+var voyageHTTPClient *http.Client = &http.Client{
+	Transport: &http.Transport{
+		MaxIdleConns:    16,
+		IdleConnTimeout: 30 * time.Second,
+	},
 }
 
-type voyageEmbeddingResponse struct {
-	Object string       `json:"object"`
-	Data   []voyageData `json:"data"`
-	Model  string       `json:"model"`
-	Usage  voyageUsage  `json:"usage"`
+type voyageAPIClient struct {
+	baseURL     string
+	authHandler func(r *http.Request) error
+
+	httpClient *http.Client
+}
+
+const voyageBaseURL = "https://api.voyageai.com/v1"
+
+func newClient(apikey string) (*voyageAPIClient, error) {
+	apikey = strings.TrimSpace(apikey)
+	return &voyageAPIClient{
+		baseURL: voyageBaseURL,
+		authHandler: func(r *http.Request) error {
+			r.Header.Set("Authorization", "Bearer "+apikey)
+			return nil
+		},
+		httpClient: voyageHTTPClient,
+	}, nil
+}
+
+func (c *voyageAPIClient) post(ctx context.Context, path string, req interface{}, resp interface{}, errorByResponse func(int, string) error) error {
+	url, err := url.JoinPath(c.baseURL, path)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("User-Agent", useragent.HTTPUserAgent)
+
+	if err := c.authHandler(r); err != nil {
+		return err
+	}
+
+	hresp, err := c.httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+
+	if hresp.StatusCode != http.StatusOK {
+		var verr voyageError
+		json.NewDecoder(hresp.Body).Decode(&verr)
+		return retryafter.Wrap(errorByResponse(hresp.StatusCode, verr.Detail), hresp.Header)
+	}
+
+	return json.NewDecoder(hresp.Body).Decode(resp)
+}
+
+func (c *voyageAPIClient) RequestEmbedding(ctx context.Context, req *voyageEmbeddingRequest) (*voyageEmbeddingResponse, error) {
+	var resp voyageEmbeddingResponse
+	if err := c.post(ctx, "/embeddings", req, &resp, getErrorByResponse); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
-type voyageData struct {
-	Object    string    `json:"object"`
-	Embedding []float64 `json:"embedding"`
-	Index     int       `json:"index"`
+func (c *voyageAPIClient) RequestMultimodalEmbedding(ctx context.Context, req *voyageMultimodalEmbeddingRequest) (*voyageEmbeddingResponse, error) {
+	var resp voyageEmbeddingResponse
+	if err := c.post(ctx, "/multimodalembeddings", req, &resp, getErrorByResponse); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
 
-type voyageUsage struct {
-	TotalTokens int `json:"total_tokens"`
+func (c *voyageAPIClient) RequestRerank(ctx context.Context, req *voyageRerankRequest) (*voyageRerankResponse, error) {
+	var resp voyageRerankResponse
+	if err := c.post(ctx, "/rerank", req, &resp, getRerankErrorByResponse); err != nil {
+		return nil, err
+	}
+	return &resp, nil
 }
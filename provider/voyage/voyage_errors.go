@@ -0,0 +1,75 @@
+package voyage
+
+import (
+	"strings"
+
+	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/rerank"
+)
+
+type voyageError struct {
+	Detail string `json:"detail"`
+}
+
+// getErrorByResponse is like getErrorByStatus, but reports inputs exceeding
+// the context window of the model as embedding.ErrMaxLengthExceeded.
+func getErrorByResponse(err_c int, detail string) error {
+	if err_c == 400 {
+		detail = strings.ToLower(detail)
+		if strings.Contains(detail, "context window") || strings.Contains(detail, "too many tokens") {
+			return embedding.ErrMaxLengthExceeded
+		}
+	}
+
+	return getErrorByStatus(err_c)
+}
+
+func getErrorByStatus(err_c int) error {
+	switch err_c {
+	case 400:
+		// invalid request (e.g. invalid model, too many inputs, too long inputs)
+		return embedding.ErrInvalidRequest
+	case 401:
+		// invalid api key
+		return embedding.ErrAuthentication
+	case 403:
+		// forbidden (e.g. ip address not allowed)
+		return embedding.ErrPermission
+	case 404:
+		return embedding.ErrNotFound
+	case 429:
+		// rate limit exceeded
+		return embedding.ErrRateLimit
+	case 500:
+		// server error
+		return embedding.ErrInternalServer
+	case 502, 503, 504:
+		// service unavailable
+		return embedding.ErrOverloaded
+	}
+	return embedding.ErrUnknown
+}
+
+func getRerankErrorByResponse(err_c int, detail string) error {
+	switch err_c {
+	case 400:
+		detail = strings.ToLower(detail)
+		if strings.Contains(detail, "context window") || strings.Contains(detail, "too many tokens") {
+			return rerank.ErrMaxLengthExceeded
+		}
+		return rerank.ErrInvalidRequest
+	case 401:
+		return rerank.ErrAuthentication
+	case 403:
+		return rerank.ErrPermission
+	case 404:
+		return rerank.ErrNotFound
+	case 429:
+		return rerank.ErrRateLimit
+	case 500:
+		return rerank.ErrInternalServer
+	case 502, 503, 504:
+		return rerank.ErrOverloaded
+	}
+	return rerank.ErrUnknown
+}
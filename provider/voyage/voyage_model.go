@@ -1 +1,398 @@
 package voyage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/internal/embedutils"
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/provider"
+)
+
+type voyageInputType string
+
+const (
+	voyageInputNone     voyageInputType = ""
+	voyageInputQuery    voyageInputType = "query"
+	voyageInputDocument voyageInputType = "document"
+)
+
+type voyageEmbeddingRequest struct {
+	Input           []string        `json:"input"`
+	Model           string          `json:"model"`
+	InputType       voyageInputType `json:"input_type,omitempty"`
+	Truncation      *bool           `json:"truncation,omitempty"`
+	OutputDimension int             `json:"output_dimension,omitempty"`
+	OutputDtype     voyageDtype     `json:"output_dtype,omitempty"`
+}
+
+type voyageDtype string
+
+const (
+	voyageDtypeFloat   voyageDtype = ""
+	voyageDtypeInt8    voyageDtype = "int8"
+	voyageDtypeUint8   voyageDtype = "uint8"
+	voyageDtypeBinary  voyageDtype = "binary"
+	voyageDtypeUbinary voyageDtype = "ubinary"
+)
+
+func convertOutputTypeVoyage(t embedding.OutputType) voyageDtype {
+	switch t {
+	case embedding.OutputTypeInt8:
+		return voyageDtypeInt8
+	case embedding.OutputTypeUint8:
+		return voyageDtypeUint8
+	case embedding.OutputTypeBinary:
+		return voyageDtypeBinary
+	case embedding.OutputTypeUbinary:
+		return voyageDtypeUbinary
+	}
+
+	return voyageDtypeFloat
+}
+
+type voyageEmbeddingResponse struct {
+	Object string       `json:"object"`
+	Data   []voyageData `json:"data"`
+	Model  string       `json:"model"`
+	Usage  voyageUsage  `json:"usage"`
+}
+
+type voyageData struct {
+	Object    string    `json:"object"`
+	Embedding []float64 `json:"embedding"`
+	Index     int       `json:"index"`
+}
+
+type voyageUsage struct {
+	TextTokens  int `json:"text_tokens,omitempty"`
+	ImagePixels int `json:"image_pixels,omitempty"`
+	TotalTokens int `json:"total_tokens"`
+}
+
+type voyageContentType string
+
+const (
+	voyageContentText        voyageContentType = "text"
+	voyageContentImageBase64 voyageContentType = "image_base64"
+	voyageContentImageURL    voyageContentType = "image_url"
+)
+
+type voyageContent struct {
+	Type        voyageContentType `json:"type"`
+	Text        string            `json:"text,omitempty"`
+	ImageBase64 string            `json:"image_base64,omitempty"` // data URL (data:image/jpeg;base64,...)
+	ImageURL    string            `json:"image_url,omitempty"`
+}
+
+type voyageMultimodalInput struct {
+	Content []voyageContent `json:"content"`
+}
+
+type voyageMultimodalEmbeddingRequest struct {
+	Inputs          []voyageMultimodalInput `json:"inputs"`
+	Model           string                  `json:"model"`
+	InputType       voyageInputType         `json:"input_type,omitempty"`
+	Truncation      *bool                   `json:"truncation,omitempty"`
+	OutputDimension int                     `json:"output_dimension,omitempty"`
+}
+
+func isMultimodalModel(model string) bool {
+	return strings.HasPrefix(model, "voyage-multimodal")
+}
+
+func convertContentVoyage(parts []llm.Segment) (voyageMultimodalInput, error) {
+	var input voyageMultimodalInput
+
+	for i := range parts {
+		switch p := parts[i].(type) {
+		case llm.Text:
+			input.Content = append(input.Content, voyageContent{
+				Type: voyageContentText,
+				Text: string(p),
+			})
+		case *llm.InlineData:
+			if !strings.HasPrefix(p.MIMEType, "image/") {
+				return input, embedding.ErrUnsupported
+			}
+			input.Content = append(input.Content, voyageContent{
+				Type:        voyageContentImageBase64,
+				ImageBase64: "data:" + p.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(p.Data),
+			})
+		case *llm.FileData:
+			if !strings.HasPrefix(p.MIMEType, "image/") {
+				return input, embedding.ErrUnsupported
+			}
+			input.Content = append(input.Content, voyageContent{
+				Type:     voyageContentImageURL,
+				ImageURL: p.FileURI,
+			})
+		default:
+			return input, embedding.ErrUnsupported
+		}
+	}
+
+	return input, nil
+}
+
+func convertTaskTypeVoyage(task embedding.TaskType) (voyageInputType, error) {
+	switch task {
+	case embedding.TaskTypeGeneral,
+		embedding.TaskTypeSemanticSimilarity,
+		embedding.TaskTypeClassification,
+		embedding.TaskTypeClustering:
+		return voyageInputNone, nil
+	case embedding.TaskTypeSearchQuery,
+		embedding.TaskTypeQA,
+		embedding.TaskTypeFactVerification:
+		return voyageInputQuery, nil
+	case embedding.TaskTypeSearchDocument:
+		return voyageInputDocument, nil
+	}
+
+	return voyageInputNone, embedding.ErrUnsupported
+}
+
+var _ embedding.Model = (*voyageEmbedding)(nil)
+var _ embedding.BatchModel = (*voyageEmbedding)(nil)
+var _ embedding.ResultModel = (*voyageEmbedding)(nil)
+var _ embedding.ContentModel = (*voyageEmbedding)(nil)
+
+// voyageMaxBatchSize is the maximum number of inputs accepted by a single embeddings request.
+const voyageMaxBatchSize = 1000
+
+type voyageEmbedding struct {
+	client *voyageAPIClient
+
+	model        string
+	outputDim    int
+	outputType   embedding.OutputType
+	normalize    bool
+	autoTruncate *bool
+}
+
+// postprocess applies the output options that the model does not support natively.
+// Multimodal models only return float embeddings.
+func (g *voyageEmbedding) postprocess(values []float64) []float64 {
+	if !isMultimodalModel(g.model) && convertOutputTypeVoyage(g.outputType) != voyageDtypeFloat {
+		return values
+	}
+
+	return embedutils.Postprocess(values, g.normalize, g.outputType)
+}
+
+func (g *voyageEmbedding) embedTexts(ctx context.Context, texts []string, task embedding.TaskType) (*embedding.Result, error) {
+	inputType, err := convertTaskTypeVoyage(task)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *voyageEmbeddingResponse
+	if isMultimodalModel(g.model) {
+		inputs := make([]voyageMultimodalInput, len(texts))
+		for i := range texts {
+			inputs[i].Content = []voyageContent{{Type: voyageContentText, Text: texts[i]}}
+		}
+
+		response, err = g.client.RequestMultimodalEmbedding(ctx, &voyageMultimodalEmbeddingRequest{
+			Inputs:          inputs,
+			Model:           g.model,
+			InputType:       inputType,
+			Truncation:      g.autoTruncate,
+			OutputDimension: g.outputDim,
+		})
+	} else {
+		response, err = g.client.RequestEmbedding(ctx, &voyageEmbeddingRequest{
+			Input:           texts,
+			Model:           g.model,
+			InputType:       inputType,
+			Truncation:      g.autoTruncate,
+			OutputDimension: g.outputDim,
+			OutputDtype:     convertOutputTypeVoyage(g.outputType),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) != len(texts) {
+		return nil, embedding.ErrNoResult
+	}
+
+	result := &embedding.Result{
+		Embeddings: make([]*embedding.Embedding, len(texts)),
+		UsageData: &embedding.UsageData{
+			InputTokens: response.Usage.TotalTokens,
+		},
+	}
+
+	for i := range response.Data {
+		index := response.Data[i].Index
+		if index < 0 || index >= len(result.Embeddings) {
+			return nil, embedding.ErrInvalidResponse
+		}
+
+		embeddings := response.Data[i].Embedding
+		if g.outputDim > 0 && len(embeddings) > g.outputDim {
+			embeddings = embeddings[:g.outputDim]
+		}
+		result.Embeddings[index] = &embedding.Embedding{Values: g.postprocess(embeddings)}
+	}
+
+	return result, nil
+}
+
+func (g *voyageEmbedding) TextEmbedding(ctx context.Context, text string, task embedding.TaskType) ([]float64, error) {
+	result, err := g.embedTexts(ctx, []string{text}, task)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Embeddings[0].Values, nil
+}
+
+func (g *voyageEmbedding) BatchTextEmbedding(ctx context.Context, texts []string, task embedding.TaskType) ([][]float64, error) {
+	result, err := g.EmbedTexts(ctx, texts, task)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Values(), nil
+}
+
+func (g *voyageEmbedding) EmbedTexts(ctx context.Context, texts []string, task embedding.TaskType) (*embedding.Result, error) {
+	return embedutils.BatchResult(texts, voyageMaxBatchSize, func(texts []string) (*embedding.Result, error) {
+		return g.embedTexts(ctx, texts, task)
+	})
+}
+
+func (g *voyageEmbedding) ContentEmbedding(ctx context.Context, parts []llm.Segment, task embedding.TaskType) ([]float64, error) {
+	if !isMultimodalModel(g.model) {
+		text, err := embedding.TextContent(parts)
+		if err != nil {
+			return nil, err
+		}
+
+		return g.TextEmbedding(ctx, text, task)
+	}
+
+	inputType, err := convertTaskTypeVoyage(task)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := convertContentVoyage(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := g.client.RequestMultimodalEmbedding(ctx, &voyageMultimodalEmbeddingRequest{
+		Inputs:          []voyageMultimodalInput{input},
+		Model:           g.model,
+		InputType:       inputType,
+		Truncation:      g.autoTruncate,
+		OutputDimension: g.outputDim,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) == 0 {
+		return nil, embedding.ErrNoResult
+	}
+
+	embeddings := response.Data[0].Embedding
+	if g.outputDim > 0 && len(embeddings) > g.outputDim {
+		embeddings = embeddings[:g.outputDim]
+	}
+
+	return g.postprocess(embeddings), nil
+}
+
+var _ provider.EmbeddingClient = (*voyageClient)(nil)
+
+type voyageClient struct {
+	client *voyageAPIClient
+}
+
+func (*voyageClient) Close() error {
+	return nil
+}
+
+func (g *voyageClient) NewEmbedding(model string, config *embedding.Config) (embedding.Model, error) {
+	if config == nil {
+		config = &embedding.Config{}
+	}
+
+	_em := &voyageEmbedding{
+		client:       g.client,
+		model:        model,
+		outputDim:    config.Dimension,
+		outputType:   config.OutputType,
+		normalize:    config.Normalize,
+		autoTruncate: config.AutoTruncate,
+	}
+
+	return _em, nil
+}
+
+var _ provider.EmbeddingProvider = Provider
+
+type VoyageProvider struct {
+}
+
+var (
+	ErrAPIKeyRequired error = errors.New("api key is required")
+)
+
+func (VoyageProvider) newVoyageClient(ctx context.Context, configs ...pconf.Config) (*voyageClient, error) {
+	client_config := pconf.GeneralConfig{}
+	for i := range configs {
+		configs[i].Apply(&client_config)
+	}
+
+	apiKey := client_config.APIKey
+
+	if apiKey == "" {
+		return nil, ErrAPIKeyRequired
+	}
+
+	_voyageClient, err := newClient(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if client_config.BaseURL != "" {
+		_voyageClient.baseURL = client_config.BaseURL
+	}
+
+	return &voyageClient{
+		client: _voyageClient,
+	}, nil
+}
+
+func (g VoyageProvider) NewEmbeddingClient(ctx context.Context, configs ...pconf.Config) (provider.EmbeddingClient, error) {
+	return g.newVoyageClient(ctx, configs...)
+}
+
+const ProviderName = "voyage"
+
+var Provider VoyageProvider
+
+func init() {
+	var exists bool
+	for _, n := range coord.ListEmbeddingProviders() {
+		if n == ProviderName {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		coord.RegisterEmbeddingProvider(ProviderName, Provider)
+	}
+}
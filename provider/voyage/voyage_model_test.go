@@ -0,0 +1,124 @@
+package voyage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/embedding"
+	"github.com/lemon-mint/coord/llm"
+	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/provider"
+	_ "github.com/lemon-mint/coord/provider/voyage"
+	"gopkg.eu.org/envloader"
+)
+
+func getClient() provider.EmbeddingClient {
+	type Config struct {
+		APIKey string `env:"VOYAGE_API_KEY"`
+	}
+	c := &Config{}
+
+	envloader.LoadAndBindEnvFile("../../.env", c)
+
+	client, err := coord.NewEmbeddingClient(
+		context.Background(),
+		"voyage",
+		pconf.WithAPIKey(c.APIKey),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+func TestVoyageTextEmbedding(t *testing.T) {
+	client := getClient()
+	defer client.Close()
+
+	model, err := client.NewEmbedding("voyage-3-lite", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	query, err := model.TextEmbedding(context.Background(), "What is the capital of France?", embedding.TaskTypeSearchQuery)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	document, err := model.TextEmbedding(context.Background(), "Paris is the capital of France.", embedding.TaskTypeSearchDocument)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(query) == 0 || len(query) != len(document) {
+		t.Errorf("unexpected embedding dimensions: %d, %d", len(query), len(document))
+		return
+	}
+}
+
+func TestVoyageBatchTextEmbedding(t *testing.T) {
+	client := getClient()
+	defer client.Close()
+
+	model, err := client.NewEmbedding("voyage-3-lite", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	texts := []string{"Apple", "Banana", "Cat", "Hamster"}
+	embeddings, err := embedding.BatchTextEmbedding(context.Background(), model, texts, embedding.TaskTypeSearchDocument)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(embeddings) != len(texts) {
+		t.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings))
+		return
+	}
+}
+
+func TestVoyageEmbedTextsUsage(t *testing.T) {
+	client := getClient()
+	defer client.Close()
+
+	model, err := client.NewEmbedding("voyage-3-lite", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := embedding.EmbedTexts(context.Background(), model, []string{"Apple", "Banana"}, embedding.TaskTypeGeneral)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if result.UsageData == nil || result.UsageData.InputTokens <= 0 {
+		t.Errorf("expected input tokens > 0, got %+v", result.UsageData)
+		return
+	}
+}
+
+func TestVoyageContentEmbeddingUnsupported(t *testing.T) {
+	client := getClient()
+	defer client.Close()
+
+	model, err := client.NewEmbedding("voyage-3-lite", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = embedding.ContentEmbedding(context.Background(), model, []llm.Segment{
+		llm.Text("A photo of a cat"),
+		&llm.InlineData{MIMEType: "image/png", Data: []byte{0x89, 0x50, 0x4e, 0x47}},
+	}, embedding.TaskTypeGeneral)
+	if !errors.Is(err, embedding.ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+		return
+	}
+}
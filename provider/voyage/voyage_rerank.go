@@ -0,0 +1,117 @@
+package voyage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/provider"
+	"github.com/lemon-mint/coord/rerank"
+)
+
+type voyageRerankRequest struct {
+	Query           string   `json:"query"`
+	Documents       []string `json:"documents"`
+	Model           string   `json:"model"`
+	TopK            int      `json:"top_k,omitempty"`
+	Truncation      *bool    `json:"truncation,omitempty"`
+	ReturnDocuments bool     `json:"return_documents"`
+}
+
+type voyageRerankData struct {
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+type voyageRerankResponse struct {
+	Object string             `json:"object"`
+	Data   []voyageRerankData `json:"data"`
+	Model  string             `json:"model"`
+	Usage  voyageUsage        `json:"usage"`
+}
+
+var _ rerank.Model = (*voyageRerank)(nil)
+
+type voyageRerank struct {
+	client *voyageAPIClient
+
+	model        string
+	topN         int
+	autoTruncate *bool
+}
+
+func (g *voyageRerank) Rerank(ctx context.Context, query string, documents []string) ([]rerank.Result, error) {
+	if len(documents) == 0 {
+		return nil, nil
+	}
+
+	response, err := g.client.RequestRerank(ctx, &voyageRerankRequest{
+		Query:      query,
+		Documents:  documents,
+		Model:      g.model,
+		TopK:       g.topN,
+		Truncation: g.autoTruncate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) == 0 {
+		return nil, rerank.ErrNoResult
+	}
+
+	results := make([]rerank.Result, len(response.Data))
+	for i := range response.Data {
+		if response.Data[i].Index < 0 || response.Data[i].Index >= len(documents) {
+			return nil, rerank.ErrInvalidResponse
+		}
+
+		results[i] = rerank.Result{
+			Index: response.Data[i].Index,
+			Score: response.Data[i].RelevanceScore,
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results, nil
+}
+
+var _ provider.RerankClient = (*voyageClient)(nil)
+
+func (g *voyageClient) NewRerank(model string, config *rerank.Config) (rerank.Model, error) {
+	if config == nil {
+		config = &rerank.Config{}
+	}
+
+	_rm := &voyageRerank{
+		client:       g.client,
+		model:        model,
+		topN:         config.TopN,
+		autoTruncate: config.AutoTruncate,
+	}
+
+	return _rm, nil
+}
+
+var _ provider.RerankProvider = Provider
+
+func (g VoyageProvider) NewRerankClient(ctx context.Context, configs ...pconf.Config) (provider.RerankClient, error) {
+	return g.newVoyageClient(ctx, configs...)
+}
+
+func init() {
+	var exists bool
+	for _, n := range coord.ListRerankProviders() {
+		if n == ProviderName {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		coord.RegisterRerankProvider(ProviderName, Provider)
+	}
+}
@@ -0,0 +1,63 @@
+package voyage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lemon-mint/coord"
+	"github.com/lemon-mint/coord/pconf"
+	"github.com/lemon-mint/coord/provider"
+	"gopkg.eu.org/envloader"
+)
+
+func getRerankClient() provider.RerankClient {
+	type Config struct {
+		APIKey string `env:"VOYAGE_API_KEY"`
+	}
+	c := &Config{}
+
+	envloader.LoadAndBindEnvFile("../../.env", c)
+
+	client, err := coord.NewRerankClient(
+		context.Background(),
+		"voyage",
+		pconf.WithAPIKey(c.APIKey),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+func TestVoyageRerank(t *testing.T) {
+	client := getRerankClient()
+	defer client.Close()
+
+	model, err := client.NewRerank("rerank-2-lite", nil)
+	if err != nil {
+		panic(err)
+	}
+
+	documents := []string{
+		"The Eiffel Tower is located in Paris.",
+		"Bananas are rich in potassium.",
+		"Paris is the capital of France.",
+	}
+
+	results, err := model.Rerank(context.Background(), "What is the capital of France?", documents)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(results) != len(documents) {
+		t.Errorf("expected %d results, got %d", len(documents), len(results))
+		return
+	}
+
+	if results[0].Index != 2 {
+		t.Errorf("expected document 2 to be ranked first, got %d", results[0].Index)
+		return
+	}
+}
@@ -14,8 +14,14 @@ var (
 	ttsProvidersMu sync.RWMutex
 	ttsProviders   = make(map[string]provider.TTSProvider)
 
+	sttProvidersMu sync.RWMutex
+	sttProviders   = make(map[string]provider.STTProvider)
+
 	embeddingProvidersMu sync.RWMutex
 	embeddingProviders   = make(map[string]provider.EmbeddingProvider)
+
+	rerankProvidersMu sync.RWMutex
+	rerankProviders   = make(map[string]provider.RerankProvider)
 )
 
 // ListLLMProviders returns the names of the registered llm providers.
@@ -70,6 +76,32 @@ func RemoveTTSProvider(name string) {
 	delete(ttsProviders, name)
 }
 
+// ListSTTProviders returns the names of the registered stt providers.
+func ListSTTProviders() []string {
+	sttProvidersMu.RLock()
+	defer sttProvidersMu.RUnlock()
+	list := make([]string, 0, len(sttProviders))
+	for name := range sttProviders {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// RegisterSTTProvider registers a stt provider.
+func RegisterSTTProvider(name string, p provider.STTProvider) {
+	sttProvidersMu.Lock()
+	defer sttProvidersMu.Unlock()
+	sttProviders[name] = p
+}
+
+// RemoveSTTProvider removes a stt provider.
+func RemoveSTTProvider(name string) {
+	sttProvidersMu.Lock()
+	defer sttProvidersMu.Unlock()
+	delete(sttProviders, name)
+}
+
 // ListEmbeddingProviders returns the names of the registered embedding providers.
 func ListEmbeddingProviders() []string {
 	embeddingProvidersMu.RLock()
@@ -95,3 +127,29 @@ func RemoveEmbeddingProvider(name string) {
 	defer embeddingProvidersMu.Unlock()
 	delete(embeddingProviders, name)
 }
+
+// ListRerankProviders returns the names of the registered rerank providers.
+func ListRerankProviders() []string {
+	rerankProvidersMu.RLock()
+	defer rerankProvidersMu.RUnlock()
+	list := make([]string, 0, len(rerankProviders))
+	for name := range rerankProviders {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// RegisterRerankProvider registers a rerank provider.
+func RegisterRerankProvider(name string, p provider.RerankProvider) {
+	rerankProvidersMu.Lock()
+	defer rerankProvidersMu.Unlock()
+	rerankProviders[name] = p
+}
+
+// RemoveRerankProvider removes a rerank provider.
+func RemoveRerankProvider(name string) {
+	rerankProvidersMu.Lock()
+	defer rerankProvidersMu.Unlock()
+	delete(rerankProviders, name)
+}
@@ -0,0 +1,17 @@
+package rerank
+
+import "errors"
+
+var (
+	ErrUnknown           = errors.New("unknown error")
+	ErrNoResult          = errors.New("no result")
+	ErrInvalidRequest    = errors.New("invalid request")
+	ErrInvalidResponse   = errors.New("invalid response")
+	ErrAuthentication    = errors.New("authentication error")
+	ErrPermission        = errors.New("permission error")
+	ErrNotFound          = errors.New("not found")
+	ErrRateLimit         = errors.New("rate limit error")
+	ErrOverloaded        = errors.New("overloaded")
+	ErrInternalServer    = errors.New("internal server error")
+	ErrMaxLengthExceeded = errors.New("max length exceeded")
+)
@@ -0,0 +1,22 @@
+package rerank
+
+import (
+	"context"
+)
+
+type Result struct {
+	Index int     `json:"index"` // Index of the document in the input documents
+	Score float64 `json:"score"` // Relevance score of the document (higher is more relevant)
+}
+
+type Config struct {
+	TopN int // Maximum number of results to return (0 returns all documents)
+
+	// AutoTruncate controls whether documents exceeding the maximum length of the model are truncated.
+	// If nil, the provider default is used.
+	AutoTruncate *bool
+}
+
+type Model interface {
+	Rerank(ctx context.Context, query string, documents []string) ([]Result, error) // Returns the documents ranked by relevance to the query, sorted by descending score.
+}
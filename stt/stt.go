@@ -0,0 +1,32 @@
+package stt
+
+import (
+	"context"
+	"time"
+
+	"github.com/lemon-mint/coord/tts"
+)
+
+type Segment struct {
+	Text    string        `json:"text"`
+	Start   time.Duration `json:"start"`             // Start offset of the segment from the beginning of the audio
+	End     time.Duration `json:"end"`               // End offset of the segment from the beginning of the audio
+	Speaker string        `json:"speaker,omitempty"` // Speaker label (Note: only available when supported by the provider)
+}
+
+type Transcription struct {
+	Text     string    `json:"text"`
+	Language string    `json:"language,omitempty"` // Language of the audio detected by the model (Note: not reported by all STT providers)
+	Segments []Segment `json:"segments,omitempty"` // Timestamped segments of the transcript (Note: not reported by all STT providers)
+}
+
+type Config struct {
+	Language string // Language of the audio (BCP-47 or ISO-639-1). Empty for automatic detection where supported.
+	Prompt   string // Text to guide the model, such as vocabulary or the preceding transcript.
+
+	SampleRate int // Sample rate of raw audio (FormatLINEAR16, FormatMULAW and FormatALAW)
+}
+
+type Model interface {
+	Transcribe(ctx context.Context, audio *tts.AudioFile) (*Transcription, error)
+}
@@ -0,0 +1,205 @@
+package tts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// defaultTelephonySampleRate is the sample rate assumed for μ-law and A-law audio.
+const defaultTelephonySampleRate = 8000
+
+// Resample converts interleaved 16-bit little-endian PCM from one sample rate
+// to another with linear interpolation. When downsampling, the frequencies above
+// the new Nyquist frequency are removed with a low-pass filter first, so that they
+// do not alias into the audible band.
+func Resample(pcm []byte, channels, from, to int) []byte {
+	if channels <= 0 {
+		channels = 1
+	}
+	if from == to || from <= 0 || to <= 0 {
+		return pcm
+	}
+
+	frames := len(pcm) / (2 * channels)
+	if frames == 0 {
+		return nil
+	}
+
+	samples := make([]float64, frames*channels)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(pcm[i*2:])))
+	}
+	if to < from {
+		samples = lowPass(samples, channels, lowPassCutoff*float64(to)/float64(from))
+	}
+
+	outFrames := int(int64(frames) * int64(to) / int64(from))
+	out := make([]byte, outFrames*channels*2)
+	ratio := float64(from) / float64(to)
+	for i := 0; i < outFrames; i++ {
+		pos := float64(i) * ratio
+		j := int(pos)
+		frac := pos - float64(j)
+		for ch := 0; ch < channels; ch++ {
+			v := samples[j*channels+ch]
+			if j+1 < frames {
+				v += (samples[(j+1)*channels+ch] - v) * frac
+			}
+			v = math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(v)))
+			binary.LittleEndian.PutUint16(out[(i*channels+ch)*2:], uint16(int16(v)))
+		}
+	}
+
+	return out
+}
+
+// lowPassCutoff is the cutoff of the anti-aliasing filter relative to the output sample rate,
+// slightly below the Nyquist frequency to leave room for the transition band.
+const lowPassCutoff = 0.45
+
+// lowPass filters interleaved samples with a Hamming-windowed sinc FIR filter.
+// cutoff is the cutoff frequency relative to the sample rate (0 to 0.5).
+func lowPass(samples []float64, channels int, cutoff float64) []float64 {
+	half := int(math.Ceil(4 / cutoff))
+	kernel := make([]float64, 2*half+1)
+
+	var sum float64
+	for k := -half; k <= half; k++ {
+		h := 2 * cutoff
+		if k != 0 {
+			x := math.Pi * float64(k)
+			h = math.Sin(2*cutoff*x) / x
+		}
+		h *= 0.54 + 0.46*math.Cos(math.Pi*float64(k)/float64(half))
+		kernel[k+half] = h
+		sum += h
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	frames := len(samples) / channels
+	out := make([]float64, len(samples))
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < channels; ch++ {
+			var v float64
+			for k := -half; k <= half; k++ {
+				// The edges are extended with the first and last sample.
+				j := min(max(i+k, 0), frames-1)
+				v += kernel[k+half] * samples[j*channels+ch]
+			}
+			out[i*channels+ch] = v
+		}
+	}
+
+	return out
+}
+
+// Downmix mixes interleaved 16-bit little-endian PCM down to a single channel.
+func Downmix(pcm []byte, channels int) []byte {
+	if channels <= 1 {
+		return pcm
+	}
+
+	frames := len(pcm) / (2 * channels)
+	out := make([]byte, frames*2)
+	for i := 0; i < frames; i++ {
+		var sum int
+		for ch := 0; ch < channels; ch++ {
+			sum += int(int16(binary.LittleEndian.Uint16(pcm[(i*channels+ch)*2:])))
+		}
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(sum/channels)))
+	}
+
+	return out
+}
+
+// decodePCM returns the audio as 16-bit little-endian PCM with its channel count and sample rate.
+func decodePCM(audio *AudioFile) (pcm []byte, channels int, sampleRate int, err error) {
+	switch audio.Format {
+	case FormatLINEAR16:
+		if audio.SampleRate == 0 {
+			return nil, 0, 0, fmt.Errorf("%w: sample rate of raw audio is unknown", ErrInvalidRequest)
+		}
+		return audio.Data, 1, audio.SampleRate, nil
+	case FormatMULAW, FormatALAW:
+		sampleRate = audio.SampleRate
+		if sampleRate == 0 {
+			sampleRate = defaultTelephonySampleRate
+		}
+		if audio.Format == FormatMULAW {
+			return DecodeMULAW(audio.Data), 1, sampleRate, nil
+		}
+		return DecodeALAW(audio.Data), 1, sampleRate, nil
+	case FormatWAV:
+		header, samples, err := ParseWAV(audio.Data)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		switch {
+		case header.AudioFormat == WAVFormatPCM && header.BitsPerSample == 16:
+			pcm = samples
+		case header.AudioFormat == WAVFormatMULAW && header.BitsPerSample == 8:
+			pcm = DecodeMULAW(samples)
+		case header.AudioFormat == WAVFormatALAW && header.BitsPerSample == 8:
+			pcm = DecodeALAW(samples)
+		default:
+			return nil, 0, 0, ErrUnsupportedFileFormat
+		}
+		return pcm, header.Channels, header.SampleRate, nil
+	}
+
+	return nil, 0, 0, ErrUnsupportedFileFormat
+}
+
+// Convert converts uncompressed audio (FormatLINEAR16, FormatWAV, FormatMULAW and FormatALAW)
+// to the format and sample rate. A zero sample rate keeps the sample rate of the audio.
+// Raw formats are mixed down to a single channel. Compressed formats are not supported.
+func Convert(audio *AudioFile, format Format, sampleRate int) (*AudioFile, error) {
+	if audio.Format == format && (sampleRate == 0 || audio.SampleRate == sampleRate) {
+		return audio, nil
+	}
+
+	pcm, channels, rate, err := decodePCM(audio)
+	if err != nil {
+		return nil, err
+	}
+
+	if sampleRate == 0 {
+		sampleRate = rate
+	}
+
+	if format != FormatWAV {
+		pcm = Downmix(pcm, channels)
+		channels = 1
+	}
+	pcm = Resample(pcm, channels, rate, sampleRate)
+
+	output := &AudioFile{
+		Format:     format,
+		SampleRate: sampleRate,
+		RequestID:  audio.RequestID,
+	}
+
+	switch format {
+	case FormatLINEAR16:
+		output.Data = pcm
+	case FormatMULAW:
+		output.Data = EncodeMULAW(pcm)
+	case FormatALAW:
+		output.Data = EncodeALAW(pcm)
+	case FormatWAV:
+		output.Data = EncodeWAV(&WAVHeader{
+			AudioFormat:   WAVFormatPCM,
+			Channels:      channels,
+			SampleRate:    sampleRate,
+			BitsPerSample: 16,
+		}, pcm)
+	default:
+		return nil, ErrUnsupportedFileFormat
+	}
+
+	return output, nil
+}
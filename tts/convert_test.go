@@ -0,0 +1,108 @@
+package tts_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/lemon-mint/coord/tts"
+)
+
+func sine(rate int, d float64) []byte {
+	return tone(rate, 440, d)
+}
+
+func tone(rate int, freq, d float64) []byte {
+	n := int(float64(rate) * d)
+	pcm := make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		v := int16(8000 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(v))
+	}
+	return pcm
+}
+
+func maxDiff(a, b []byte) int {
+	var d int
+	for i := 0; i+1 < len(a) && i+1 < len(b); i += 2 {
+		x := int(int16(binary.LittleEndian.Uint16(a[i:])))
+		y := int(int16(binary.LittleEndian.Uint16(b[i:])))
+		if x-y > d {
+			d = x - y
+		}
+		if y-x > d {
+			d = y - x
+		}
+	}
+	return d
+}
+
+func TestG711RoundTrip(t *testing.T) {
+	pcm := sine(8000, 0.1)
+
+	if d := maxDiff(pcm, tts.DecodeMULAW(tts.EncodeMULAW(pcm))); d > 256 {
+		t.Errorf("mulaw round trip error too large: %d", d)
+	}
+	if d := maxDiff(pcm, tts.DecodeALAW(tts.EncodeALAW(pcm))); d > 256 {
+		t.Errorf("alaw round trip error too large: %d", d)
+	}
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	pcm := sine(16000, 0.1)
+	header := &tts.WAVHeader{AudioFormat: tts.WAVFormatPCM, Channels: 1, SampleRate: 16000, BitsPerSample: 16}
+
+	parsed, samples, err := tts.ParseWAV(tts.EncodeWAV(header, pcm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *parsed != *header || len(samples) != len(pcm) {
+		t.Errorf("unexpected wav: %+v, %d bytes", parsed, len(samples))
+	}
+}
+
+func TestConvert(t *testing.T) {
+	audio := &tts.AudioFile{Format: tts.FormatLINEAR16, Data: sine(24000, 1), SampleRate: 24000}
+
+	out, err := tts.Convert(audio, tts.FormatMULAW, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Format != tts.FormatMULAW || out.SampleRate != 8000 || len(out.Data) != 8000 {
+		t.Errorf("unexpected output: %s %d Hz, %d bytes", out.Format, out.SampleRate, len(out.Data))
+	}
+
+	wav, err := tts.Convert(out, tts.FormatWAV, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, samples, err := tts.ParseWAV(wav.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.SampleRate != 8000 || len(samples) != 16000 {
+		t.Errorf("unexpected wav: %+v, %d bytes", header, len(samples))
+	}
+
+	if _, err := tts.Convert(&tts.AudioFile{Format: tts.FormatMP3}, tts.FormatWAV, 0); err != tts.ErrUnsupportedFileFormat {
+		t.Errorf("expected ErrUnsupportedFileFormat, got %v", err)
+	}
+}
+
+func peak(pcm []byte) int {
+	return maxDiff(pcm, make([]byte, len(pcm)))
+}
+
+func TestResampleAntiAliasing(t *testing.T) {
+	// A 6 kHz tone is above the Nyquist frequency of 8 kHz audio and would alias to 2 kHz.
+	// The edges are skipped, since the filter can not remove the steps at the start and end of the tone.
+	out := tts.Resample(tone(24000, 6000, 0.1), 1, 24000, 8000)
+	if p := peak(out[100 : len(out)-100]); p > 100 {
+		t.Errorf("6 kHz tone not filtered: peak %d", p)
+	}
+
+	// A 440 Hz tone passes through.
+	if p := peak(tts.Resample(sine(24000, 0.1), 1, 24000, 8000)); p < 7500 {
+		t.Errorf("440 Hz tone attenuated: peak %d", p)
+	}
+}
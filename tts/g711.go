@@ -0,0 +1,127 @@
+package tts
+
+import "encoding/binary"
+
+const (
+	mulawBias = 0x84
+	mulawClip = 32635
+)
+
+func linearToMulaw(s int16) byte {
+	v := int(s)
+	var sign int
+	if v < 0 {
+		v = -v
+		sign = 0x80
+	}
+	if v > mulawClip {
+		v = mulawClip
+	}
+	v += mulawBias
+
+	exp := 7
+	for mask := 0x4000; v&mask == 0 && exp > 0; mask >>= 1 {
+		exp--
+	}
+	mantissa := (v >> (exp + 3)) & 0x0F
+
+	return ^byte(sign | exp<<4 | mantissa)
+}
+
+func mulawToLinear(u byte) int16 {
+	u = ^u
+	exp := (u >> 4) & 0x07
+	v := ((int(u&0x0F) << 3) + mulawBias) << exp
+	v -= mulawBias
+
+	if u&0x80 != 0 {
+		return int16(-v)
+	}
+	return int16(v)
+}
+
+var alawSegmentEnd = [8]int{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+
+func linearToAlaw(s int16) byte {
+	v := int(s) >> 3
+
+	mask := 0xD5
+	if v < 0 {
+		mask = 0x55
+		v = -v - 1
+	}
+
+	seg := 0
+	for seg < len(alawSegmentEnd) && v > alawSegmentEnd[seg] {
+		seg++
+	}
+	if seg >= len(alawSegmentEnd) {
+		return byte(0x7F ^ mask)
+	}
+
+	a := seg << 4
+	if seg < 2 {
+		a |= (v >> 1) & 0x0F
+	} else {
+		a |= (v >> seg) & 0x0F
+	}
+
+	return byte(a ^ mask)
+}
+
+func alawToLinear(a byte) int16 {
+	a ^= 0x55
+	t := int(a&0x0F) << 4
+	seg := int(a&0x70) >> 4
+
+	switch seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= seg - 1
+	}
+
+	if a&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
+
+// EncodeMULAW encodes 16-bit little-endian PCM samples with G.711 μ-law.
+func EncodeMULAW(pcm []byte) []byte {
+	out := make([]byte, len(pcm)/2)
+	for i := range out {
+		out[i] = linearToMulaw(int16(binary.LittleEndian.Uint16(pcm[i*2:])))
+	}
+	return out
+}
+
+// DecodeMULAW decodes G.711 μ-law samples to 16-bit little-endian PCM.
+func DecodeMULAW(data []byte) []byte {
+	out := make([]byte, len(data)*2)
+	for i := range data {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(mulawToLinear(data[i])))
+	}
+	return out
+}
+
+// EncodeALAW encodes 16-bit little-endian PCM samples with G.711 A-law.
+func EncodeALAW(pcm []byte) []byte {
+	out := make([]byte, len(pcm)/2)
+	for i := range out {
+		out[i] = linearToAlaw(int16(binary.LittleEndian.Uint16(pcm[i*2:])))
+	}
+	return out
+}
+
+// DecodeALAW decodes G.711 A-law samples to 16-bit little-endian PCM.
+func DecodeALAW(data []byte) []byte {
+	out := make([]byte, len(data)*2)
+	for i := range data {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(alawToLinear(data[i])))
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package tts
+
+import (
+	"context"
+	"io"
+)
+
+type AudioStream struct {
+	Format Format `json:"mime"`  // Format of the concatenated audio chunks (Note: set before the first chunk is delivered)
+	Err    error  `json:"error"` // Only Available after Stream channel is closed
+
+	Stream <-chan []byte `json:"-"` // Audio chunks in arrival order
+}
+
+// Wait drains the stream and returns the error that ended it.
+func (g *AudioStream) Wait() error {
+	if g == nil {
+		return ErrNoResponse
+	}
+
+	for range g.Stream {
+	}
+
+	return g.Err
+}
+
+// Reader returns an io.Reader over the audio chunks of the stream.
+// The reader returns the error of the stream after the last chunk.
+func (g *AudioStream) Reader() io.Reader {
+	return &audioStreamReader{s: g}
+}
+
+type audioStreamReader struct {
+	s   *AudioStream
+	buf []byte
+}
+
+func (r *audioStreamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk, ok := <-r.s.Stream
+		if !ok {
+			if r.s.Err != nil {
+				return 0, r.s.Err
+			}
+			return 0, io.EOF
+		}
+		r.buf = chunk
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// GenerateSpeechStream synthesizes the text as a stream of audio chunks.
+// If the model implements StreamModel, the audio is streamed from the provider,
+// otherwise the result of GenerateSpeech is delivered as a single chunk.
+func GenerateSpeechStream(ctx context.Context, m Model, text string) *AudioStream {
+	if sm, ok := m.(StreamModel); ok {
+		return sm.GenerateSpeechStream(ctx, text)
+	}
+
+	stream := make(chan []byte, 1)
+	v := &AudioStream{
+		Stream: stream,
+	}
+
+	go func() {
+		defer close(stream)
+
+		audio, err := m.GenerateSpeech(ctx, text)
+		if err != nil {
+			v.Err = err
+			return
+		}
+
+		v.Format = audio.Format
+		stream <- audio.Data
+	}()
+
+	return v
+}
@@ -0,0 +1,42 @@
+package tts_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/lemon-mint/coord/tts"
+)
+
+type staticModel struct {
+	audio *tts.AudioFile
+	err   error
+}
+
+func (m staticModel) GenerateSpeech(ctx context.Context, text string) (*tts.AudioFile, error) {
+	return m.audio, m.err
+}
+
+func TestGenerateSpeechStreamFallback(t *testing.T) {
+	m := staticModel{audio: &tts.AudioFile{Format: tts.FormatMP3, Data: []byte("audio")}}
+
+	stream := tts.GenerateSpeechStream(context.Background(), m, "hello")
+	data, err := io.ReadAll(stream.Reader())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "audio" || stream.Format != tts.FormatMP3 {
+		t.Errorf("unexpected stream output: %q (%s)", data, stream.Format)
+	}
+}
+
+func TestGenerateSpeechStreamError(t *testing.T) {
+	m := staticModel{err: tts.ErrRateLimit}
+
+	stream := tts.GenerateSpeechStream(context.Background(), m, "hello")
+	if err := stream.Wait(); !errors.Is(err, tts.ErrRateLimit) {
+		t.Errorf("expected ErrRateLimit, got %v", err)
+	}
+}
@@ -17,9 +17,37 @@ const (
 	FormatWAV      Format = "audio/wav"
 )
 
+// Extension returns the conventional file name extension of the format, including the leading dot.
+func (f Format) Extension() string {
+	switch f {
+	case FormatLINEAR16:
+		return ".pcm"
+	case FormatMP3:
+		return ".mp3"
+	case FormatOGG:
+		return ".ogg"
+	case FormatALAW:
+		return ".alaw"
+	case FormatMULAW:
+		return ".ulaw"
+	case FormatAAC:
+		return ".aac"
+	case FormatFLAC:
+		return ".flac"
+	case FormatWAV:
+		return ".wav"
+	}
+
+	return ""
+}
+
 type AudioFile struct {
 	Format Format `json:"mime"`
 	Data   []byte `json:"data"`
+
+	SampleRate int `json:"sampleRate,omitempty"` // Sample rate of the audio (Note: only set for uncompressed formats when known)
+
+	RequestID string `json:"requestId,omitempty"` // ID of the synthesis request (Note: only available when reported by the provider)
 }
 
 type Config struct {
@@ -48,3 +76,33 @@ type Config struct {
 type Model interface {
 	GenerateSpeech(ctx context.Context, text string) (*AudioFile, error)
 }
+
+// SpeechContext describes the speech surrounding a request, so that consecutive
+// requests are synthesized with continuous prosody.
+type SpeechContext struct {
+	PreviousText       string   // Text spoken right before the request
+	NextText           string   // Text spoken right after the request
+	PreviousRequestIDs []string // RequestIDs of the audio spoken before the request, oldest first
+}
+
+// ContextModel is implemented by models that can condition the speech on the surrounding text.
+type ContextModel interface {
+	Model
+	GenerateSpeechWithContext(ctx context.Context, text string, sc *SpeechContext) (*AudioFile, error)
+}
+
+// GenerateSpeechWithContext synthesizes the text with the surrounding speech context.
+// If the model does not implement ContextModel, the context is ignored.
+func GenerateSpeechWithContext(ctx context.Context, m Model, text string, sc *SpeechContext) (*AudioFile, error) {
+	if cm, ok := m.(ContextModel); ok && sc != nil {
+		return cm.GenerateSpeechWithContext(ctx, text, sc)
+	}
+
+	return m.GenerateSpeech(ctx, text)
+}
+
+// StreamModel is implemented by models that can deliver audio while it is being synthesized.
+type StreamModel interface {
+	Model
+	GenerateSpeechStream(ctx context.Context, text string) *AudioStream
+}
@@ -0,0 +1,88 @@
+package tts
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidWAV = errors.New("invalid wav file")
+
+// WAV audio format codes
+const (
+	WAVFormatPCM   = 1
+	WAVFormatALAW  = 6
+	WAVFormatMULAW = 7
+)
+
+type WAVHeader struct {
+	AudioFormat   int
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+}
+
+// ParseWAV returns the header and the sample data of a WAV file.
+// A data chunk with a placeholder size, as written by streaming encoders, extends to the end of the file.
+func ParseWAV(data []byte) (*WAVHeader, []byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, nil, ErrInvalidWAV
+	}
+
+	var header *WAVHeader
+	p := 12
+	for p+8 <= len(data) {
+		id := string(data[p : p+4])
+		size := int(binary.LittleEndian.Uint32(data[p+4 : p+8]))
+		p += 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 || p+16 > len(data) {
+				return nil, nil, ErrInvalidWAV
+			}
+			header = &WAVHeader{
+				AudioFormat:   int(binary.LittleEndian.Uint16(data[p : p+2])),
+				Channels:      int(binary.LittleEndian.Uint16(data[p+2 : p+4])),
+				SampleRate:    int(binary.LittleEndian.Uint32(data[p+4 : p+8])),
+				BitsPerSample: int(binary.LittleEndian.Uint16(data[p+14 : p+16])),
+			}
+		case "data":
+			if header == nil {
+				return nil, nil, ErrInvalidWAV
+			}
+			if size < 0 || p+size > len(data) {
+				size = len(data) - p
+			}
+			return header, data[p : p+size], nil
+		}
+
+		if size < 0 || p+size > len(data) {
+			break
+		}
+		p += size + size&1
+	}
+
+	return nil, nil, ErrInvalidWAV
+}
+
+// EncodeWAV wraps the sample data in a WAV file.
+func EncodeWAV(header *WAVHeader, samples []byte) []byte {
+	blockAlign := header.Channels * header.BitsPerSample / 8
+
+	out := make([]byte, 44, 44+len(samples))
+	copy(out[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(out[4:8], uint32(36+len(samples)))
+	copy(out[8:12], "WAVE")
+	copy(out[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(out[16:20], 16)
+	binary.LittleEndian.PutUint16(out[20:22], uint16(header.AudioFormat))
+	binary.LittleEndian.PutUint16(out[22:24], uint16(header.Channels))
+	binary.LittleEndian.PutUint32(out[24:28], uint32(header.SampleRate))
+	binary.LittleEndian.PutUint32(out[28:32], uint32(header.SampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(out[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(out[34:36], uint16(header.BitsPerSample))
+	copy(out[36:40], "data")
+	binary.LittleEndian.PutUint32(out[40:44], uint32(len(samples)))
+
+	return append(out, samples...)
+}